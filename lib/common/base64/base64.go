@@ -5,6 +5,7 @@ package base64
 
 import (
 	b64 "encoding/base64"
+	"errors"
 )
 
 // i2p base64 alphabet
@@ -13,6 +14,9 @@ const Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789
 // i2p base64 encoding
 var I2PEncoding *b64.Encoding = b64.NewEncoding(Alphabet)
 
+// error returned by DecodeFromStringConstantTime for malformed data
+var ErrMalformed = errors.New("illegal base64 data")
+
 //
 // Return a go string of the I2P base64
 // encoding of the provided byte slice
@@ -28,3 +32,61 @@ func EncodeToString(data []byte) string {
 func DecodeFromString(str string) (d []byte, err error) {
 	return I2PEncoding.DecodeString(str)
 }
+
+//
+// decode string using i2p base64 encoding without branching or indexing
+// tables on the characters of the string, for decoding secret key material.
+// unlike DecodeFromString newlines are not skipped.
+// returns error if data is malformed
+//
+func DecodeFromStringConstantTime(str string) (d []byte, err error) {
+	if len(str)%4 != 0 {
+		err = ErrMalformed
+		return
+	}
+	// the amount of padding follows from the length of the key, which is not secret
+	padding := 0
+	for padding < 2 && len(str) > padding && str[len(str)-1-padding] == '=' {
+		padding++
+	}
+	body := str[:len(str)-padding]
+	d = make([]byte, 0, len(body)*3/4)
+	invalid := 0
+	acc := 0
+	bits := 0
+	for i := 0; i < len(body); i++ {
+		v := decodeChar(body[i])
+		invalid |= v
+		acc = (acc<<6 | (v & 0x3f)) & 0xfff
+		bits += 6
+		if bits >= 8 {
+			bits -= 8
+			d = append(d, byte(acc>>uint(bits)))
+		}
+	}
+	if invalid < 0 || len(body)%4 == 1 {
+		d = nil
+		err = ErrMalformed
+	}
+	return
+}
+
+//
+// return the value of an i2p base64 character or -1 if it is not in the
+// alphabet. each range check masks in its offset when c falls in the range.
+//
+func decodeChar(c byte) int {
+	ch := int(c)
+	ret := -1
+	// A-Z
+	ret += (((0x40 - ch) & (ch - 0x5b)) >> 8) & (ch - 0x40)
+	// a-z
+	ret += (((0x60 - ch) & (ch - 0x7b)) >> 8) & (ch - 0x46)
+	// 0-9
+	ret += (((0x2f - ch) & (ch - 0x3a)) >> 8) & (ch + 0x05)
+	// -
+	ret += (((0x2c - ch) & (ch - 0x2e)) >> 8) & 0x3f
+	// ~
+	ret += (((0x7d - ch) & (ch - 0x7f)) >> 8) & 0x40
+	return ret
+}
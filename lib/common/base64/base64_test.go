@@ -0,0 +1,52 @@
+package base64
+
+import (
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecodeFromStringConstantTimeMatchesDecodeFromString(t *testing.T) {
+	assert := assert.New(t)
+
+	for n := 0; n < 400; n++ {
+		data := make([]byte, n)
+		_, err := rand.Read(data)
+		assert.Nil(err)
+		str := EncodeToString(data)
+		expected, err := DecodeFromString(str)
+		assert.Nil(err)
+		decoded, err := DecodeFromStringConstantTime(str)
+		assert.Nil(err)
+		assert.Equal(expected, decoded, "length %d", n)
+	}
+}
+
+func TestDecodeFromStringConstantTimeDecodesAlphabet(t *testing.T) {
+	assert := assert.New(t)
+
+	expected, err := DecodeFromString(Alphabet)
+	assert.Nil(err)
+	decoded, err := DecodeFromStringConstantTime(Alphabet)
+	assert.Nil(err)
+	assert.Equal(expected, decoded)
+}
+
+func TestDecodeFromStringConstantTimeRejectsMalformedData(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, str := range []string{"AAA", "AA+A", "AA/A", "A=AA", "A===", "AA=A"} {
+		_, err := DecodeFromString(str)
+		assert.NotNil(err, "%q", str)
+		decoded, err := DecodeFromStringConstantTime(str)
+		assert.Nil(decoded, "%q", str)
+		assert.Equal(ErrMalformed, err, "%q", str)
+	}
+}
+
+func TestDecodeFromStringConstantTimeDoesNotSkipNewlines(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := DecodeFromStringConstantTime("AAAA\nAAA=")
+	assert.Equal(ErrMalformed, err)
+}
@@ -28,6 +28,7 @@ payload :: data
 
 import (
 	"errors"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -46,6 +47,12 @@ const (
 	CERT_MIN_SIZE = 3
 )
 
+// Payload sizes of SIGNED Certificates, with and without the signing Destination's Hash
+const (
+	CERT_SIGNED_SIZE           = 40
+	CERT_SIGNED_WITH_HASH_SIZE = 72
+)
+
 type Certificate []byte
 
 //
@@ -78,7 +85,10 @@ func (certificate Certificate) Length() (length int, err error) {
 	if err != nil {
 		return
 	}
-	length = Integer(certificate[1:CERT_MIN_SIZE])
+	length, _, err = NewInteger(certificate[1:], CERT_MIN_SIZE-1)
+	if err != nil {
+		return
+	}
 	inferred_len := length + CERT_MIN_SIZE
 	if inferred_len > cert_len {
 		log.WithFields(log.Fields{
@@ -102,6 +112,37 @@ func (certificate Certificate) Length() (length int, err error) {
 	return
 }
 
+//
+// Check that the length declared in the Certificate matches the number of payload
+// bytes present, returning an error if the payload is truncated or followed by
+// extra data.
+//
+func (certificate Certificate) ValidateLength() (err error) {
+	_, err = certificate.Type()
+	if err != nil {
+		return
+	}
+	length, _, err := NewInteger(certificate[1:], CERT_MIN_SIZE-1)
+	if err != nil {
+		return
+	}
+	payload_len := len(certificate) - CERT_MIN_SIZE
+	if length > payload_len {
+		err = errors.New("error parsing certificate: declared length exceeds payload")
+	} else if length < payload_len {
+		err = errors.New("error parsing certificate: payload exceeds declared length")
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"at":                       "(Certificate) ValidateLength",
+			"certificate_length_field": length,
+			"payload_length":           payload_len,
+			"reason":                   err.Error(),
+		}).Error("invalid certificate")
+	}
+	return
+}
+
 //
 // Return the Certificate data and any errors encountered parsing the Certificate.
 //
@@ -123,6 +164,184 @@ func (certificate Certificate) Data() (data []byte, err error) {
 	return
 }
 
+//
+// Return the hashcash string stored in the payload of a HASHCASH Certificate,
+// or an error if the Certificate is of a different type.
+//
+func (certificate Certificate) HashCashData() (hashcash string, err error) {
+	err = certificate.checkType(CERT_HASHCASH, "(Certificate) HashCashData")
+	if err != nil {
+		return
+	}
+	data, err := certificate.Data()
+	if err != nil {
+		return
+	}
+	hashcash = string(data)
+	return
+}
+
+//
+// Return the Signature stored in the payload of a SIGNED Certificate, along with
+// the Hash of the signing Destination if one is included, or an error if the
+// Certificate is of a different type or the payload is not a valid size.
+//
+func (certificate Certificate) SignedData() (signature Signature, signer *Hash, err error) {
+	err = certificate.checkType(CERT_SIGNED, "(Certificate) SignedData")
+	if err != nil {
+		return
+	}
+	data, err := certificate.Data()
+	if err != nil {
+		return
+	}
+	switch len(data) {
+	case CERT_SIGNED_SIZE:
+		signature = Signature(data)
+	case CERT_SIGNED_WITH_HASH_SIZE:
+		signature = Signature(data[:CERT_SIGNED_SIZE])
+		signer = new(Hash)
+		copy(signer[:], data[CERT_SIGNED_SIZE:])
+	default:
+		log.WithFields(log.Fields{
+			"at":       "(Certificate) SignedData",
+			"data_len": len(data),
+			"reason":   "payload is not 40 or 72 bytes",
+		}).Error("invalid certificate")
+		err = errors.New("error parsing signed certificate: invalid payload length")
+	}
+	return
+}
+
+//
+// Return the Certificates embedded one after another in the payload of a MULTIPLE
+// Certificate, or an error if the Certificate is of a different type or an embedded
+// Certificate is truncated.
+//
+func (certificate Certificate) SubCertificates() (certificates []Certificate, err error) {
+	err = certificate.checkType(CERT_MULTIPLE, "(Certificate) SubCertificates")
+	if err != nil {
+		return
+	}
+	remainder, err := certificate.Data()
+	if err != nil {
+		return
+	}
+	for len(remainder) > 0 {
+		var sub_certificate Certificate
+		sub_certificate, remainder, err = ReadCertificate(remainder)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"at":     "(Certificate) SubCertificates",
+				"index":  len(certificates),
+				"reason": err.Error(),
+			}).Error("invalid certificate")
+			err = errors.New("error parsing multiple certificate: truncated sub certificate")
+			certificates = nil
+			return
+		}
+		certificates = append(certificates, sub_certificate)
+	}
+	return
+}
+
+//
+// Return an error if the Certificate is not of the expected type.
+//
+func (certificate Certificate) checkType(expected int, at string) (err error) {
+	cert_type, err := certificate.Type()
+	if err != nil {
+		return
+	}
+	if cert_type != expected {
+		log.WithFields(log.Fields{
+			"at":            at,
+			"cert_type":     cert_type,
+			"expected_type": expected,
+			"reason":        "certificate type mismatch",
+		}).Error("invalid certificate")
+		err = errors.New("error parsing certificate: certificate type mismatch")
+	}
+	return
+}
+
+//
+// Return a human readable summary of the Certificate's type and payload length.
+//
+func (certificate Certificate) String() string {
+	cert_type, err := certificate.Type()
+	if err != nil {
+		return fmt.Sprintf("Certificate{invalid: %d bytes}", len(certificate))
+	}
+	length, _ := certificate.Length()
+	return fmt.Sprintf("Certificate{type: %s, length: %d}", certificateTypeName(cert_type), length)
+}
+
+//
+// Return the name of a Certificate type for display.
+//
+func certificateTypeName(cert_type int) string {
+	names := map[int]string{
+		CERT_NULL:     "NULL",
+		CERT_HASHCASH: "HASHCASH",
+		CERT_HIDDEN:   "HIDDEN",
+		CERT_SIGNED:   "SIGNED",
+		CERT_MULTIPLE: "MULTIPLE",
+		CERT_KEY:      "KEY",
+	}
+	if name, ok := names[cert_type]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", cert_type)
+}
+
+//
+// Create the three byte NULL Certificate used by legacy Destinations and RouterIdentities.
+//
+func NewNullCertificate() Certificate {
+	return Certificate([]byte{CERT_NULL, 0x00, 0x00})
+}
+
+//
+// Create a Certificate of a known type carrying payload, returning an error if the type
+// is not one of the CERT_* constants or the payload is too long for the length field.
+//
+func NewCertificateWithType(cert_type int, payload []byte) (certificate Certificate, err error) {
+	if cert_type < CERT_NULL || cert_type > CERT_KEY {
+		log.WithFields(log.Fields{
+			"at":        "NewCertificateWithType",
+			"cert_type": cert_type,
+			"reason":    "unknown certificate type",
+		}).Error("error creating certificate")
+		err = errors.New("error creating certificate: unknown certificate type")
+		return
+	}
+	return NewRawCertificate(byte(cert_type), payload)
+}
+
+//
+// Create a Certificate with any type byte carrying payload, for experimenting with
+// certificate types this package does not know about.  Returns an error if the payload is
+// too long for the length field.
+//
+func NewRawCertificate(cert_type byte, payload []byte) (certificate Certificate, err error) {
+	if len(payload) > 0xffff {
+		log.WithFields(log.Fields{
+			"at":          "NewRawCertificate",
+			"payload_len": len(payload),
+			"reason":      "payload too long",
+		}).Error("error creating certificate")
+		err = errors.New("error creating certificate: payload too long")
+		return
+	}
+	certificate = make(Certificate, CERT_MIN_SIZE, CERT_MIN_SIZE+len(payload))
+	certificate[0] = cert_type
+	certificate[1] = byte(len(payload) >> 8)
+	certificate[2] = byte(len(payload))
+	certificate = append(certificate, payload...)
+	return
+}
+
 //
 // Read a Certificate from a slice of bytes, returning any extra data on the end of the slice
 // and any errors if a valid Certificate could not be read.
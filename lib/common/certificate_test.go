@@ -146,3 +146,195 @@ func TestReadCertificateWithInvalidLength(t *testing.T) {
 		assert.Equal("error parsing certificate length: certificate is too short", err.Error(), "correct error message should be returned")
 	}
 }
+
+func TestHashCashDataReturnsPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate := Certificate([]byte{CERT_HASHCASH, 0x00, 0x03, 'a', 'b', 'c'})
+	hashcash, err := certificate.HashCashData()
+
+	assert.Nil(err)
+	assert.Equal("abc", hashcash, "certificate.HashCashData() did not return the payload")
+}
+
+func TestHashCashDataErrorsOnTypeMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate := Certificate([]byte{CERT_SIGNED, 0x00, 0x00})
+	_, err := certificate.HashCashData()
+
+	if assert.NotNil(err) {
+		assert.Equal("error parsing certificate: certificate type mismatch", err.Error())
+	}
+}
+
+func TestSignedDataWithoutHash(t *testing.T) {
+	assert := assert.New(t)
+
+	bytes := []byte{CERT_SIGNED, 0x00, CERT_SIGNED_SIZE}
+	bytes = append(bytes, make([]byte, CERT_SIGNED_SIZE)...)
+	signature, signer, err := Certificate(bytes).SignedData()
+
+	assert.Nil(err)
+	assert.Equal(CERT_SIGNED_SIZE, len(signature))
+	assert.Nil(signer, "certificate.SignedData() returned a signer hash when none was present")
+}
+
+func TestSignedDataWithHash(t *testing.T) {
+	assert := assert.New(t)
+
+	bytes := []byte{CERT_SIGNED, 0x00, CERT_SIGNED_WITH_HASH_SIZE}
+	bytes = append(bytes, make([]byte, CERT_SIGNED_SIZE)...)
+	for i := 0; i < 32; i++ {
+		bytes = append(bytes, 0x07)
+	}
+	signature, signer, err := Certificate(bytes).SignedData()
+
+	assert.Nil(err)
+	assert.Equal(CERT_SIGNED_SIZE, len(signature))
+	if assert.NotNil(signer) {
+		assert.Equal(byte(0x07), signer[0])
+		assert.Equal(byte(0x07), signer[31])
+	}
+}
+
+func TestSignedDataErrorsOnTypeMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate := Certificate([]byte{CERT_HASHCASH, 0x00, 0x00})
+	_, _, err := certificate.SignedData()
+
+	if assert.NotNil(err) {
+		assert.Equal("error parsing certificate: certificate type mismatch", err.Error())
+	}
+}
+
+func TestSignedDataErrorsOnInvalidLength(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate := Certificate([]byte{CERT_SIGNED, 0x00, 0x01, 0x00})
+	_, _, err := certificate.SignedData()
+
+	if assert.NotNil(err) {
+		assert.Equal("error parsing signed certificate: invalid payload length", err.Error())
+	}
+}
+
+func TestCertificateStringShowsTypeAndLength(t *testing.T) {
+	assert := assert.New(t)
+
+	str := Certificate([]byte{CERT_KEY, 0x00, 0x04, 0x00, 0x07, 0x00, 0x00}).String()
+
+	assert.Contains(str, "type: KEY")
+	assert.Contains(str, "length: 4")
+}
+
+func TestNewNullCertificateIsReadable(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate, remainder, err := ReadCertificate(NewNullCertificate())
+	assert.Nil(err)
+	assert.Equal(0, len(remainder))
+	cert_type, err := certificate.Type()
+	assert.Nil(err)
+	assert.Equal(CERT_NULL, cert_type)
+	length, err := certificate.Length()
+	assert.Nil(err)
+	assert.Equal(0, length)
+}
+
+func TestValidateLengthAcceptsMatchingPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(Certificate([]byte{CERT_KEY, 0x00, 0x02, 0xff, 0xff}).ValidateLength())
+	assert.Nil(NewNullCertificate().ValidateLength())
+}
+
+func TestValidateLengthReportsTruncatedPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	err := Certificate([]byte{CERT_KEY, 0x00, 0x04, 0xff, 0xff}).ValidateLength()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing certificate: declared length exceeds payload", err.Error())
+	}
+}
+
+func TestValidateLengthReportsExtraPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	err := Certificate([]byte{CERT_KEY, 0x00, 0x01, 0xff, 0xff}).ValidateLength()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing certificate: payload exceeds declared length", err.Error())
+	}
+}
+
+func TestNewCertificateWithTypeBuildsKeyCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate, err := NewCertificateWithType(CERT_KEY, []byte{0x00, 0x07, 0x00, 0x04})
+	assert.Nil(err)
+	assert.Equal(Certificate([]byte{CERT_KEY, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04}), certificate)
+	assert.Nil(certificate.ValidateLength())
+}
+
+func TestNewCertificateWithTypeRejectsUnknownType(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewCertificateWithType(CERT_KEY+1, nil)
+	if assert.NotNil(err) {
+		assert.Equal("error creating certificate: unknown certificate type", err.Error())
+	}
+	_, err = NewCertificateWithType(-1, nil)
+	assert.NotNil(err)
+}
+
+func TestNewRawCertificateAllowsUnknownType(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate, err := NewRawCertificate(0x42, []byte{0x01})
+	assert.Nil(err)
+	cert_type, err := certificate.Type()
+	assert.Nil(err)
+	assert.Equal(0x42, cert_type)
+	assert.Nil(certificate.ValidateLength())
+
+	_, err = NewRawCertificate(0x42, make([]byte, 0x10000))
+	if assert.NotNil(err) {
+		assert.Equal("error creating certificate: payload too long", err.Error())
+	}
+}
+
+func TestSubCertificatesReadsEmbeddedCertificates(t *testing.T) {
+	assert := assert.New(t)
+
+	payload := append(NewNullCertificate(), NewNullCertificate()...)
+	certificate, err := NewCertificateWithType(CERT_MULTIPLE, payload)
+	assert.Nil(err)
+	sub_certificates, err := certificate.SubCertificates()
+	assert.Nil(err)
+	if assert.Equal(2, len(sub_certificates)) {
+		for _, sub_certificate := range sub_certificates {
+			assert.Equal(NewNullCertificate(), sub_certificate)
+		}
+	}
+}
+
+func TestSubCertificatesReportsTruncatedCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate, err := NewCertificateWithType(CERT_MULTIPLE, []byte{CERT_NULL, 0x00, 0x00, CERT_KEY, 0x00, 0x04, 0x00})
+	assert.Nil(err)
+	_, err = certificate.SubCertificates()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing multiple certificate: truncated sub certificate", err.Error())
+	}
+}
+
+func TestSubCertificatesRejectsOtherTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewNullCertificate().SubCertificates()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing certificate: certificate type mismatch", err.Error())
+	}
+}
@@ -7,6 +7,7 @@ Accurate for version 0.9.24
 */
 
 import (
+	"encoding/binary"
 	"time"
 )
 
@@ -18,7 +19,18 @@ type Date [8]byte
 // struct.
 //
 func (date Date) Time() (date_time time.Time) {
-	seconds := Integer(date[:])
-	date_time = time.Unix(0, int64(seconds*1000000))
+	milliseconds := int64(Integer(date[:]))
+	date_time = time.Unix(milliseconds/1000, (milliseconds%1000)*int64(time.Millisecond))
+	return
+}
+
+//
+// NewDate converts a Go time.Time to a Date, rounding it down to millisecond precision.
+// Seconds and nanoseconds are converted separately so times outside the range of
+// UnixNano are encoded correctly.
+//
+func NewDate(t time.Time) (date Date) {
+	milliseconds := t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+	binary.BigEndian.PutUint64(date[:], uint64(milliseconds))
 	return
 }
@@ -2,7 +2,9 @@ package common
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math/rand"
 	"testing"
+	"time"
 )
 
 func TestTimeFromMiliseconds(t *testing.T) {
@@ -13,3 +15,38 @@ func TestTimeFromMiliseconds(t *testing.T) {
 
 	assert.Equal(int64(86400), go_time.Unix(), "Date.Time() did not parse time in milliseconds")
 }
+
+func TestNewDateRoundTrips(t *testing.T) {
+	assert := assert.New(t)
+
+	date := NewDate(time.Unix(86400, 0))
+	assert.Equal(Date{0x00, 0x00, 0x00, 0x00, 0x05, 0x26, 0x5c, 0x00}, date)
+	assert.Equal(int64(86400), date.Time().Unix())
+}
+
+func TestNewDateTimeRoundTripsRandomTimes(t *testing.T) {
+	assert := assert.New(t)
+
+	// up to the end of year 9999, well past the range of UnixNano
+	const max_seconds = 253402300799
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		go_time := time.Unix(rng.Int63n(max_seconds), rng.Int63n(int64(time.Second)))
+		date := NewDate(go_time)
+		assert.True(
+			go_time.Truncate(time.Millisecond).Equal(date.Time()),
+			"Date did not round trip %s, got %s", go_time, date.Time(),
+		)
+		assert.Equal(date, NewDate(date.Time()))
+	}
+}
+
+func TestNewDateTruncatesSubMillisecondPrecision(t *testing.T) {
+	assert := assert.New(t)
+
+	date := NewDate(time.Unix(86400, int64(time.Millisecond)-1))
+	assert.Equal(Date{0x00, 0x00, 0x00, 0x00, 0x05, 0x26, 0x5c, 0x00}, date)
+
+	date = NewDate(time.Unix(86400, int64(time.Millisecond)+1))
+	assert.Equal(Date{0x00, 0x00, 0x00, 0x00, 0x05, 0x26, 0x5c, 0x01}, date)
+}
@@ -9,12 +9,19 @@ Identical to KeysAndCert
 */
 
 import (
+	"errors"
 	"github.com/go-i2p/go-i2p/lib/common/base32"
 	"github.com/go-i2p/go-i2p/lib/common/base64"
 	"github.com/go-i2p/go-i2p/lib/crypto"
+	log "github.com/sirupsen/logrus"
 	"strings"
 )
 
+// Suffix of I2P base32 addresses
+const (
+	B32_SUFFIX = ".b32.i2p"
+)
+
 //
 // A Destination is a KeysAndCert with functionallity
 // for generating base32 and base64 addresses.
@@ -33,6 +40,18 @@ func (destination Destination) Certificate() (Certificate, error) {
 	return KeysAndCert(destination).Certificate()
 }
 
+//
+// Return the type of the Certificate in this Destination, such as CERT_KEY.
+//
+func (destination Destination) CertificateType() (cert_type int, err error) {
+	cert, err := destination.Certificate()
+	if err != nil {
+		return
+	}
+	cert_type, err = cert.Type()
+	return
+}
+
 func ReadDestination(data []byte) (destination Destination, remainder []byte, err error) {
 	keys_and_cert, remainder, err := ReadKeysAndCert(data)
 	destination = Destination(keys_and_cert)
@@ -45,7 +64,38 @@ func ReadDestination(data []byte) (destination Destination, remainder []byte, er
 func (destination Destination) Base32Address() (str string) {
 	hash := crypto.SHA256(destination)
 	str = strings.Trim(base32.EncodeToString(hash[:]), "=")
-	str = str + ".b32.i2p"
+	str = str + B32_SUFFIX
+	return
+}
+
+//
+// Decode an I2P base32 address back to the Hash of the Destination it refers to,
+// returning an error if the address lacks the .b32.i2p suffix or does not decode
+// to a 32 byte hash.
+//
+func ResolveB32(addr string) (hash Hash, err error) {
+	addr = strings.ToLower(addr)
+	if !strings.HasSuffix(addr, B32_SUFFIX) {
+		log.WithFields(log.Fields{
+			"at":      "ResolveB32",
+			"address": addr,
+			"reason":  "missing .b32.i2p suffix",
+		}).Error("error resolving b32 address")
+		err = errors.New("error resolving b32 address: missing .b32.i2p suffix")
+		return
+	}
+	decoded, err := base32.DecodeFromString(strings.TrimSuffix(addr, B32_SUFFIX))
+	if err != nil || len(decoded) != len(hash) {
+		log.WithFields(log.Fields{
+			"at":       "ResolveB32",
+			"address":  addr,
+			"data_len": len(decoded),
+			"reason":   "label does not decode to a 32 byte hash",
+		}).Error("error resolving b32 address")
+		err = errors.New("error resolving b32 address: invalid base32 label")
+		return
+	}
+	copy(hash[:], decoded)
 	return
 }
 
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestResolveB32ReturnsDestinationHash(t *testing.T) {
+	assert := assert.New(t)
+
+	destination := Destination(buildDestination())
+	hash, err := ResolveB32(destination.Base32Address())
+
+	assert.Nil(err)
+	assert.Equal(HashData(destination), hash, "ResolveB32() did not return the hash of the destination")
+}
+
+func TestResolveB32AcceptsUpperCase(t *testing.T) {
+	assert := assert.New(t)
+
+	destination := Destination(buildDestination())
+	hash, err := ResolveB32(strings.ToUpper(destination.Base32Address()))
+
+	assert.Nil(err)
+	assert.Equal(HashData(destination), hash)
+}
+
+func TestResolveB32RejectsMissingSuffix(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ResolveB32("aaaa.i2p")
+	if assert.NotNil(err) {
+		assert.Equal("error resolving b32 address: missing .b32.i2p suffix", err.Error())
+	}
+}
+
+func TestResolveB32RejectsMalformedLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, address := range []string{"aaaa.b32.i2p", "1111.b32.i2p", ".b32.i2p"} {
+		_, err := ResolveB32(address)
+		if assert.NotNil(err, "ResolveB32() accepted %s", address) {
+			assert.Equal("error resolving b32 address: invalid base32 label", err.Error())
+		}
+	}
+}
+
+func TestCertificateTypeReportsInvalidDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Destination(make([]byte, 10)).CertificateType()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing KeysAndCert: data is smaller than minimum valid size", err.Error())
+	}
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"encoding/hex"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	log "github.com/sirupsen/logrus"
+)
+
+// Number of bytes either side of the offset included in a structure dump
+const (
+	DUMP_STRUCTURE_CONTEXT = 32
+)
+
+//
+// Log the bytes of a structure around offset as hex and I2P base64 to help diagnose
+// a failed parse.  Nothing is logged unless debug logging is enabled.
+//
+func DumpStructure(name string, data []byte, offset int) {
+	if !log.IsLevelEnabled(log.DebugLevel) {
+		return
+	}
+	start := offset - DUMP_STRUCTURE_CONTEXT
+	if start < 0 {
+		start = 0
+	}
+	end := offset + DUMP_STRUCTURE_CONTEXT
+	if end > len(data) {
+		end = len(data)
+	}
+	if start > end {
+		start = end
+	}
+	snippet := data[start:end]
+	log.WithFields(log.Fields{
+		"at":        "DumpStructure",
+		"structure": name,
+		"data_len":  len(data),
+		"offset":    offset,
+		"start":     start,
+		"hex":       hex.EncodeToString(snippet),
+		"base64":    base64.EncodeToString(snippet),
+	}).Debug("structure dump")
+}
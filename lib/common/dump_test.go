@@ -0,0 +1,57 @@
+package common
+
+import (
+	"bytes"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// capture everything logged at or above level for the rest of the test, restoring the
+// standard logger's output and level when the test finishes
+func captureLog(t *testing.T, level log.Level) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	logger := log.StandardLogger()
+	out, old_level := logger.Out, logger.GetLevel()
+	log.SetOutput(buf)
+	log.SetLevel(level)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetLevel(old_level)
+	})
+	return buf
+}
+
+func TestDumpStructureLogsHexAroundOffset(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, 100)
+	data[50] = 0xab
+	data[51] = 0xcd
+	output := captureLog(t, log.DebugLevel)
+	DumpStructure("Test", data, 50)
+	assert.Contains(output.String(), "structure=Test")
+	assert.Contains(output.String(), "start=18")
+	assert.Contains(output.String(), "abcd")
+}
+
+func TestDumpStructureSilentWithoutDebug(t *testing.T) {
+	assert := assert.New(t)
+
+	output := captureLog(t, log.InfoLevel)
+	DumpStructure("Test", []byte{0xab, 0xcd}, 0)
+	assert.Equal("", output.String())
+}
+
+func TestReadRouterAddressDumpsTruncatedMapping(t *testing.T) {
+	assert := assert.New(t)
+
+	// replace the empty options Mapping with a truncated one
+	router_address_bytes := []byte(buildRouterAddressWithOptions("foo", map[string]string{}))
+	router_address_bytes = append(router_address_bytes[:len(router_address_bytes)-2], 0x00, 0x10, 0xee)
+	output := captureLog(t, log.DebugLevel)
+	_, _, err := ReadRouterAddress(router_address_bytes)
+	assert.NotNil(err)
+	assert.Contains(output.String(), "structure=RouterAddress")
+	assert.Contains(output.String(), "0010ee")
+}
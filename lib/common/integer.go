@@ -8,6 +8,8 @@ Accurate for version 0.9.24
 
 import (
 	"encoding/binary"
+	"errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // Total byte length of an I2P integer
@@ -17,16 +19,99 @@ const (
 
 //
 // Interpret a slice of bytes from length 0 to length 8 as a big-endian
-// integer and return an int representation.
+// integer and return an int representation.  Integers are read in place
+// without padding them out to 8 bytes, so parsing does not allocate.
 //
 func Integer(number []byte) (value int) {
-	num_len := len(number)
-	if num_len < INTEGER_SIZE {
-		number = append(
-			make([]byte, INTEGER_SIZE-num_len),
-			number...,
-		)
+	switch num_len := len(number); {
+	case num_len == 1:
+		value = int(number[0])
+	case num_len == 2:
+		value = int(binary.BigEndian.Uint16(number))
+	case num_len == 4:
+		value = int(binary.BigEndian.Uint32(number))
+	case num_len >= INTEGER_SIZE:
+		value = int(binary.BigEndian.Uint64(number))
+	default:
+		for _, b := range number {
+			value = value<<8 | int(b)
+		}
 	}
-	value = int(binary.BigEndian.Uint64(number))
+	return
+}
+
+//
+// Interpret a slice of bytes like Integer, returning an error instead of a negative
+// value if the Integer is too large for an int.  Use this wherever the value is used
+// as a length or offset.
+//
+func NonNegativeInteger(number []byte) (value int, err error) {
+	value = Integer(number)
+	if value < 0 {
+		log.WithFields(log.Fields{
+			"at":       "NonNegativeInteger",
+			"data_len": len(number),
+			"reason":   "integer overflows int",
+		}).Error("error parsing integer")
+		err = errors.New("error parsing integer: value overflows int")
+		value = 0
+	}
+	return
+}
+
+//
+// Read an Integer of size bytes from the front of a slice of bytes, returning the
+// value, any data beyond the Integer, and an error if fewer than size bytes are
+// available or the value is too large for an int.
+//
+func NewInteger(bytes []byte, size int) (value int, remainder []byte, err error) {
+	bytes_len := len(bytes)
+	if bytes_len < size {
+		log.WithFields(log.Fields{
+			"at":           "NewInteger",
+			"data_len":     bytes_len,
+			"required_len": size,
+			"reason":       "not enough data",
+		}).Error("error parsing integer")
+		err = errors.New("error parsing integer: not enough data")
+		return
+	}
+	value, err = NonNegativeInteger(bytes[:size])
+	if err != nil {
+		return
+	}
+	remainder = bytes[size:]
+	return
+}
+
+//
+// Encode value as a big-endian I2P Integer exactly size bytes wide.  I2P Integers
+// are between 1 and 8 bytes, so an error is returned for any other size or if the
+// value is negative or does not fit in size bytes.
+//
+func NewIntegerFromInt(value int, size int) (number []byte, err error) {
+	if size < 1 || size > INTEGER_SIZE {
+		log.WithFields(log.Fields{
+			"at":       "NewIntegerFromInt",
+			"size":     size,
+			"max_size": INTEGER_SIZE,
+			"reason":   "invalid integer size",
+		}).Error("error creating integer")
+		err = errors.New("error creating integer: size must be between 1 and 8 bytes")
+		return
+	}
+	if value < 0 || (size < INTEGER_SIZE && uint64(value)>>(uint(size)*8) != 0) {
+		log.WithFields(log.Fields{
+			"at":     "NewIntegerFromInt",
+			"size":   size,
+			"value":  value,
+			"reason": "value does not fit in size bytes",
+		}).Error("error creating integer")
+		err = errors.New("error creating integer: value does not fit in size bytes")
+		return
+	}
+	buf := make([]byte, INTEGER_SIZE)
+	binary.BigEndian.PutUint64(buf, uint64(value))
+	number = buf[INTEGER_SIZE-size:]
 	return
 }
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/binary"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -29,3 +30,120 @@ func TestIsZeroWithNoData(t *testing.T) {
 
 	assert.Equal(integer, 0, "Integer() did not correctly parse zero length byte slice")
 }
+
+func TestNewIntegerReturnsRemainder(t *testing.T) {
+	assert := assert.New(t)
+
+	integer, remainder, err := NewInteger([]byte{0x00, 0x02, 0x03}, 2)
+
+	assert.Nil(err)
+	assert.Equal(2, integer, "NewInteger() did not parse the leading bytes")
+	assert.Equal([]byte{0x03}, remainder, "NewInteger() did not return the correct remainder")
+}
+
+func TestNewIntegerErrorsWithEmptyBuffer(t *testing.T) {
+	assert := assert.New(t)
+
+	integer, remainder, err := NewInteger([]byte{}, 1)
+
+	if assert.NotNil(err) {
+		assert.Equal("error parsing integer: not enough data", err.Error())
+	}
+	assert.Equal(0, integer)
+	assert.Equal(0, len(remainder))
+}
+
+func TestNewIntegerFromIntWithMaxSize(t *testing.T) {
+	assert := assert.New(t)
+
+	number, err := NewIntegerFromInt(1, 8)
+
+	assert.Nil(err)
+	assert.Equal([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, number)
+	assert.Equal(1, Integer(number), "NewIntegerFromInt() did not round trip through Integer()")
+}
+
+func TestNewIntegerFromIntErrorsOnExcessWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	number, err := NewIntegerFromInt(1, 9)
+
+	if assert.NotNil(err) {
+		assert.Equal("error creating integer: size must be between 1 and 8 bytes", err.Error())
+	}
+	assert.Nil(number)
+}
+
+func TestNewIntegerFromIntErrorsWhenValueTooLarge(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewIntegerFromInt(256, 1)
+
+	if assert.NotNil(err) {
+		assert.Equal("error creating integer: value does not fit in size bytes", err.Error())
+	}
+}
+
+// reference implementation padding the integer out to 8 bytes
+func paddedInteger(number []byte) int {
+	if len(number) < INTEGER_SIZE {
+		number = append(make([]byte, INTEGER_SIZE-len(number)), number...)
+	}
+	return int(binary.BigEndian.Uint64(number))
+}
+
+func TestIntegerMatchesPaddedIntegerForAllWidths(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x7f, 0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10}
+	for width := 0; width <= len(data); width++ {
+		assert.Equal(paddedInteger(data[:width]), Integer(data[:width]), "Integer() disagrees with padded parse for width %d", width)
+	}
+}
+
+func TestIntegerDoesNotAllocate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	allocs := testing.AllocsPerRun(100, func() {
+		for width := 0; width <= INTEGER_SIZE; width++ {
+			Integer(data[:width])
+		}
+	})
+	assert.Equal(float64(0), allocs)
+}
+
+func TestNonNegativeIntegerRejectsOverflow(t *testing.T) {
+	assert := assert.New(t)
+
+	value, err := NonNegativeInteger([]byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	assert.Nil(err)
+	assert.True(value > 0)
+
+	value, err = NonNegativeInteger([]byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	if assert.NotNil(err) {
+		assert.Equal("error parsing integer: value overflows int", err.Error())
+	}
+	assert.Equal(0, value)
+}
+
+func TestNewIntegerRejectsOverflow(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, err := NewInteger([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, 8)
+	assert.NotNil(err, "NewInteger() returned a negative length without an error")
+}
+
+// keeps benchmarked results alive so the calls are not optimized away
+var integer_sink int
+
+func BenchmarkInteger(b *testing.B) {
+	data := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		integer_sink += Integer(data[:1])
+		integer_sink += Integer(data[:2])
+		integer_sink += Integer(data[:4])
+		integer_sink += Integer(data[:8])
+	}
+}
@@ -48,6 +48,10 @@ const (
 // Key Certificate Public Key Types
 const (
 	KEYCERT_CRYPTO_ELG = iota
+	KEYCERT_CRYPTO_P256
+	KEYCERT_CRYPTO_P384
+	KEYCERT_CRYPTO_P521
+	KEYCERT_CRYPTO_X25519
 )
 
 // SigningPublicKey sizes for Signing Key Types
@@ -63,9 +67,18 @@ const (
 	KEYCERT_SIGN_ED25519PH_SIZE = 32
 )
 
+// Signature size for legacy DSA SHA1 Signing Keys
+const (
+	KEYCERT_SIGN_DSA_SHA1_SIG_SIZE = 40
+)
+
 // PublicKey sizes for Public Key Types
 const (
-	KEYCERT_CRYPTO_ELG_SIZE = 256
+	KEYCERT_CRYPTO_ELG_SIZE    = 256
+	KEYCERT_CRYPTO_P256_SIZE   = 64
+	KEYCERT_CRYPTO_P384_SIZE   = 96
+	KEYCERT_CRYPTO_P521_SIZE   = 132
+	KEYCERT_CRYPTO_X25519_SIZE = 32
 )
 
 // Sizes of structures in KeyCertificates
@@ -74,6 +87,41 @@ const (
 	KEYCERT_SPK_SIZE    = 128
 )
 
+// SigningPublicKey sizes indexed by Signing Key Type
+var signing_public_key_sizes = map[int]int{
+	KEYCERT_SIGN_DSA_SHA1:  KEYCERT_SIGN_DSA_SHA1_SIZE,
+	KEYCERT_SIGN_P256:      KEYCERT_SIGN_P256_SIZE,
+	KEYCERT_SIGN_P384:      KEYCERT_SIGN_P384_SIZE,
+	KEYCERT_SIGN_P521:      KEYCERT_SIGN_P521_SIZE,
+	KEYCERT_SIGN_RSA2048:   KEYCERT_SIGN_RSA2048_SIZE,
+	KEYCERT_SIGN_RSA3072:   KEYCERT_SIGN_RSA3072_SIZE,
+	KEYCERT_SIGN_RSA4096:   KEYCERT_SIGN_RSA4096_SIZE,
+	KEYCERT_SIGN_ED25519:   KEYCERT_SIGN_ED25519_SIZE,
+	KEYCERT_SIGN_ED25519PH: KEYCERT_SIGN_ED25519PH_SIZE,
+}
+
+// PublicKey sizes indexed by Public Key Type
+var crypto_public_key_sizes = map[int]int{
+	KEYCERT_CRYPTO_ELG:    KEYCERT_CRYPTO_ELG_SIZE,
+	KEYCERT_CRYPTO_P256:   KEYCERT_CRYPTO_P256_SIZE,
+	KEYCERT_CRYPTO_P384:   KEYCERT_CRYPTO_P384_SIZE,
+	KEYCERT_CRYPTO_P521:   KEYCERT_CRYPTO_P521_SIZE,
+	KEYCERT_CRYPTO_X25519: KEYCERT_CRYPTO_X25519_SIZE,
+}
+
+// Signature sizes for Signing Key Types
+var signature_sizes = map[int]int{
+	KEYCERT_SIGN_DSA_SHA1:  KEYCERT_SIGN_DSA_SHA1_SIG_SIZE,
+	KEYCERT_SIGN_P256:      64,
+	KEYCERT_SIGN_P384:      96,
+	KEYCERT_SIGN_P521:      132,
+	KEYCERT_SIGN_RSA2048:   256,
+	KEYCERT_SIGN_RSA3072:   384,
+	KEYCERT_SIGN_RSA4096:   512,
+	KEYCERT_SIGN_ED25519:   64,
+	KEYCERT_SIGN_ED25519PH: 64,
+}
+
 type KeyCertificate []byte
 
 //
@@ -160,6 +208,12 @@ func (key_certificate KeyCertificate) ConstructPublicKey(data []byte) (public_ke
 		var elg_key crypto.ElgPublicKey
 		copy(elg_key[:], data[KEYCERT_PUBKEY_SIZE-KEYCERT_CRYPTO_ELG_SIZE:KEYCERT_PUBKEY_SIZE])
 		public_key = elg_key
+	case KEYCERT_CRYPTO_X25519:
+		// Crypto public keys shorter than the field are stored at its start,
+		// followed by padding.
+		var x25519_key crypto.X25519PublicKey
+		copy(x25519_key[:], data[:KEYCERT_CRYPTO_X25519_SIZE])
+		public_key = x25519_key
 	}
 	return
 }
@@ -169,7 +223,7 @@ func (key_certificate KeyCertificate) ConstructPublicKey(data []byte) (public_ke
 // it along with any errors encountered constructing the SigningPublicKey.
 //
 func (key_certificate KeyCertificate) ConstructSigningPublicKey(data []byte) (signing_public_key crypto.SigningPublicKey, err error) {
-	signing_key_type, err := key_certificate.PublicKeyType()
+	signing_key_type, err := key_certificate.SigningPublicKeyType()
 	if err != nil {
 		return
 	}
@@ -199,9 +253,13 @@ func (key_certificate KeyCertificate) ConstructSigningPublicKey(data []byte) (si
 		signing_public_key = ec_key
 	case KEYCERT_SIGN_P521:
 		var ec_key crypto.ECP521PublicKey
-		extra := KEYCERT_SIGN_P521_SIZE - KEYCERT_SPK_SIZE
-		copy(ec_key[:], data)
-		copy(ec_key[KEYCERT_SPK_SIZE:], key_certificate[4:4+extra])
+		extra, eerr := key_certificate.excessSigningKeyData()
+		if eerr != nil {
+			err = eerr
+			return
+		}
+		copy(ec_key[:], data[:KEYCERT_SPK_SIZE])
+		copy(ec_key[KEYCERT_SPK_SIZE:], extra)
 		signing_public_key = ec_key
 	case KEYCERT_SIGN_RSA2048:
 		//var rsa_key crypto.RSA2048PublicKey
@@ -212,27 +270,100 @@ func (key_certificate KeyCertificate) ConstructSigningPublicKey(data []byte) (si
 	case KEYCERT_SIGN_RSA3072:
 	case KEYCERT_SIGN_RSA4096:
 	case KEYCERT_SIGN_ED25519:
+		ed_key := make(crypto.Ed25519PublicKey, KEYCERT_SIGN_ED25519_SIZE)
+		copy(ed_key, data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_ED25519_SIZE:KEYCERT_SPK_SIZE])
+		signing_public_key = ed_key
 	case KEYCERT_SIGN_ED25519PH:
 	}
 	return
 }
 
+//
+// Return the part of a large SigningPublicKey that does not fit in the KEYCERT_SPK_SIZE
+// byte field and is stored in the Key Certificate after the two key types.
+//
+func (key_certificate KeyCertificate) excessSigningKeyData() (extra []byte, err error) {
+	signing_key_type, err := key_certificate.SigningPublicKeyType()
+	if err != nil {
+		return
+	}
+	err = key_certificate.ValidateKeyLengths()
+	if err != nil {
+		return
+	}
+	data, _ := key_certificate.Data()
+	excess := signing_public_key_sizes[signing_key_type] - KEYCERT_SPK_SIZE
+	if excess > 0 {
+		extra = data[4 : 4+excess]
+	}
+	return
+}
+
+//
+// Return the number of padding bytes between the PublicKey field and a SigningPublicKey of
+// the Key Certificate's signing type in a KeysAndCert, or an error if the signing type is
+// unknown or its key is too large for the field.
+//
+func (key_certificate KeyCertificate) PaddingLength() (padding_len int, err error) {
+	signing_key_type, err := key_certificate.SigningPublicKeyType()
+	if err != nil {
+		return
+	}
+	signing_key_len, known := signing_public_key_sizes[signing_key_type]
+	if !known || signing_key_len > KEYCERT_SPK_SIZE {
+		log.WithFields(log.Fields{
+			"at":               "(KeyCertificate) PaddingLength",
+			"signing_key_type": signing_key_type,
+			"signing_key_len":  signing_key_len,
+			"reason":           "signing key does not fit in its field",
+		}).Error("error parsing key certificate")
+		err = errors.New("error parsing key certificate: signing key does not fit in its field")
+		return
+	}
+	padding_len = KEYCERT_SPK_SIZE - signing_key_len
+	return
+}
+
+//
+// Check that the Key Certificate carries the excess data of any SigningPublicKey or
+// PublicKey too large for its field in a KeysAndCert.  Excess signing key data comes
+// first, followed by excess public key data.
+//
+func (key_certificate KeyCertificate) ValidateKeyLengths() (err error) {
+	signing_key_type, err := key_certificate.SigningPublicKeyType()
+	if err != nil {
+		return
+	}
+	pubkey_type, err := key_certificate.PublicKeyType()
+	if err != nil {
+		return
+	}
+	required := 4
+	if excess := signing_public_key_sizes[signing_key_type] - KEYCERT_SPK_SIZE; excess > 0 {
+		required += excess
+	}
+	if excess := crypto_public_key_sizes[pubkey_type] - KEYCERT_PUBKEY_SIZE; excess > 0 {
+		required += excess
+	}
+	data, _ := key_certificate.Data()
+	data_len := len(data)
+	if data_len < required {
+		log.WithFields(log.Fields{
+			"at":           "(KeyCertificate) ValidateKeyLengths",
+			"data_len":     data_len,
+			"required_len": required,
+			"reason":       "key certificate missing excess key data",
+		}).Error("error parsing key certificate")
+		err = errors.New("error parsing key certificate: missing excess key data")
+	}
+	return
+}
+
 //
 // Return the size of a Signature corresponding to the Key Certificate's
 // SigningPublicKey type.
 //
 func (key_certificate KeyCertificate) SignatureSize() (size int) {
-	sizes := map[int]int{
-		KEYCERT_SIGN_DSA_SHA1:  40,
-		KEYCERT_SIGN_P256:      64,
-		KEYCERT_SIGN_P384:      96,
-		KEYCERT_SIGN_P521:      132,
-		KEYCERT_SIGN_RSA2048:   256,
-		KEYCERT_SIGN_RSA3072:   384,
-		KEYCERT_SIGN_RSA4096:   512,
-		KEYCERT_SIGN_ED25519:   64,
-		KEYCERT_SIGN_ED25519PH: 64,
-	}
 	key_type, err := key_certificate.SigningPublicKeyType()
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -242,5 +373,51 @@ func (key_certificate KeyCertificate) SignatureSize() (size int) {
 		}).Error("error getting signature size")
 		return 0
 	}
-	return sizes[int(key_type)]
+	return signature_sizes[int(key_type)]
+}
+
+//
+// Return the Signing Key Types that ConstructSigningPublicKey can build a SigningPublicKey
+// for, in ascending order, so callers can tell when a peer uses a type this package cannot
+// verify.
+//
+func SupportedSigningTypes() (signing_key_types []int) {
+	for signing_key_type := KEYCERT_SIGN_DSA_SHA1; signing_key_type <= KEYCERT_SIGN_ED25519PH; signing_key_type++ {
+		key_certificate := newKeyCertificate(signing_key_type, KEYCERT_CRYPTO_ELG)
+		signing_public_key, err := key_certificate.ConstructSigningPublicKey(make([]byte, KEYCERT_SPK_SIZE))
+		if err == nil && signing_public_key != nil {
+			signing_key_types = append(signing_key_types, signing_key_type)
+		}
+	}
+	return
+}
+
+//
+// Return the Public Key Types that ConstructPublicKey can build a PublicKey for, in
+// ascending order.
+//
+func SupportedCryptoTypes() (pubkey_types []int) {
+	for pubkey_type := KEYCERT_CRYPTO_ELG; pubkey_type <= KEYCERT_CRYPTO_X25519; pubkey_type++ {
+		key_certificate := newKeyCertificate(KEYCERT_SIGN_DSA_SHA1, pubkey_type)
+		public_key, err := key_certificate.ConstructPublicKey(make([]byte, KEYCERT_PUBKEY_SIZE))
+		if err == nil && public_key != nil {
+			pubkey_types = append(pubkey_types, pubkey_type)
+		}
+	}
+	return
+}
+
+//
+// Build a Key Certificate for the given key types carrying zeroed excess key data.
+//
+func newKeyCertificate(signing_key_type, pubkey_type int) KeyCertificate {
+	payload := []byte{byte(signing_key_type >> 8), byte(signing_key_type), byte(pubkey_type >> 8), byte(pubkey_type)}
+	if excess := signing_public_key_sizes[signing_key_type] - KEYCERT_SPK_SIZE; excess > 0 {
+		payload = append(payload, make([]byte, excess)...)
+	}
+	if excess := crypto_public_key_sizes[pubkey_type] - KEYCERT_PUBKEY_SIZE; excess > 0 {
+		payload = append(payload, make([]byte, excess)...)
+	}
+	certificate, _ := NewCertificateWithType(CERT_KEY, payload)
+	return KeyCertificate(certificate)
 }
@@ -125,3 +125,47 @@ func TestConstructSigningPublicKeyWithP521(t *testing.T) {
 	assert.Nil(err, "ConstructSigningPublicKey() with P521 returned err on valid data")
 	assert.Equal(spk.Len(), KEYCERT_SIGN_P521_SIZE, "ConstructSigningPublicKey() with P521 returned incorrect SigningPublicKey length")
 }
+
+func TestPaddingLengthForEd25519(t *testing.T) {
+	assert := assert.New(t)
+
+	key_cert := KeyCertificate([]byte{CERT_KEY, 0x00, 0x04, 0x00, KEYCERT_SIGN_ED25519, 0x00, 0x00})
+	padding_len, err := key_cert.PaddingLength()
+	assert.Nil(err)
+	assert.Equal(96, padding_len)
+}
+
+func TestPaddingLengthForDSA(t *testing.T) {
+	assert := assert.New(t)
+
+	key_cert := KeyCertificate([]byte{CERT_KEY, 0x00, 0x04, 0x00, KEYCERT_SIGN_DSA_SHA1, 0x00, 0x00})
+	padding_len, err := key_cert.PaddingLength()
+	assert.Nil(err)
+	assert.Equal(0, padding_len)
+}
+
+func TestPaddingLengthRejectsOversizedSigningKey(t *testing.T) {
+	assert := assert.New(t)
+
+	key_cert := KeyCertificate([]byte{CERT_KEY, 0x00, 0x08, 0x00, KEYCERT_SIGN_P521, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	_, err := key_cert.PaddingLength()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing key certificate: signing key does not fit in its field", err.Error())
+	}
+}
+
+func TestSupportedSigningTypesListsEd25519AndDSA(t *testing.T) {
+	assert := assert.New(t)
+
+	signing_key_types := SupportedSigningTypes()
+	assert.Contains(signing_key_types, KEYCERT_SIGN_DSA_SHA1)
+	assert.Contains(signing_key_types, KEYCERT_SIGN_ED25519)
+	assert.NotContains(signing_key_types, KEYCERT_SIGN_RSA2048)
+	assert.NotContains(signing_key_types, KEYCERT_SIGN_ED25519PH)
+}
+
+func TestSupportedCryptoTypesListsElGamalAndX25519(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]int{KEYCERT_CRYPTO_ELG, KEYCERT_CRYPTO_X25519}, SupportedCryptoTypes())
+}
@@ -46,7 +46,9 @@ total length: 387+ bytes
 */
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/go-i2p/go-i2p/lib/crypto"
 	log "github.com/sirupsen/logrus"
 )
@@ -78,7 +80,7 @@ func (keys_and_cert KeysAndCert) PublicKey() (key crypto.PublicKey, err error) {
 		// No Certificate is present, return the KEYS_AND_CERT_PUBKEY_SIZE byte
 		// PublicKey space as ElgPublicKey.
 		var elg_key crypto.ElgPublicKey
-		copy(keys_and_cert[:KEYS_AND_CERT_PUBKEY_SIZE], elg_key[:])
+		copy(elg_key[:], keys_and_cert[:KEYS_AND_CERT_PUBKEY_SIZE])
 		key = elg_key
 	} else {
 		// A Certificate is present in this KeysAndCert
@@ -95,7 +97,7 @@ func (keys_and_cert KeysAndCert) PublicKey() (key crypto.PublicKey, err error) {
 			// PublicKey space as ElgPublicKey.  No other Certificate
 			// types are currently in use.
 			var elg_key crypto.ElgPublicKey
-			copy(keys_and_cert[:KEYS_AND_CERT_PUBKEY_SIZE], elg_key[:])
+			copy(elg_key[:], keys_and_cert[:KEYS_AND_CERT_PUBKEY_SIZE])
 			key = elg_key
 			log.WithFields(log.Fields{
 				"at":        "(KeysAndCert) PublicKey",
@@ -169,12 +171,79 @@ func (keys_and_cert KeysAndCert) Certificate() (cert Certificate, err error) {
 	return
 }
 
+//
+// Return the payload of this KeysAndCert's Certificate.  For a Key Certificate this is
+// the two key types followed by any key data too large for the fixed key fields.
+// Returns nil if the Certificate cannot be read.
+//
+func (keys_and_cert KeysAndCert) CertificatePayload() []byte {
+	cert, err := keys_and_cert.Certificate()
+	if err != nil {
+		return nil
+	}
+	data, err := cert.Data()
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+//
+// Return the size of Signatures made by this KeysAndCert's signing key, as specified
+// by its Key Certificate if present or the size of a legacy DSA SHA1 Signature.
+//
+func (keys_and_cert KeysAndCert) signatureSize() int {
+	cert, err := keys_and_cert.Certificate()
+	if err == nil {
+		if cert_type, _ := cert.Type(); cert_type == CERT_KEY {
+			return KeyCertificate(cert).SignatureSize()
+		}
+	}
+	return KEYCERT_SIGN_DSA_SHA1_SIG_SIZE
+}
+
+//
+// Return a human readable summary of the KeysAndCert with the key material
+// abbreviated to its leading bytes.
+//
+func (keys_and_cert KeysAndCert) String() string {
+	if len(keys_and_cert) < KEYS_AND_CERT_MIN_SIZE {
+		return fmt.Sprintf("KeysAndCert{invalid: %d bytes}", len(keys_and_cert))
+	}
+	cert, _ := keys_and_cert.Certificate()
+	return fmt.Sprintf(
+		"KeysAndCert{public_key: %s, signing_key: %s, certificate: %s}",
+		abbreviateBytes(keys_and_cert[:KEYS_AND_CERT_PUBKEY_SIZE]),
+		abbreviateBytes(keys_and_cert[KEYS_AND_CERT_PUBKEY_SIZE:KEYS_AND_CERT_DATA_SIZE]),
+		cert,
+	)
+}
+
+//
+// Render the first few bytes of a key as hex followed by the total length.
+//
+func abbreviateBytes(data []byte) string {
+	const shown = 4
+	if len(data) <= shown {
+		return hex.EncodeToString(data)
+	}
+	return fmt.Sprintf("%s...(%d bytes)", hex.EncodeToString(data[:shown]), len(data))
+}
+
 //
 // Read a KeysAndCert from a slice of bytes, retuning it and the remaining data as well as any errors
 // encoutered parsing the KeysAndCert.
 //
 func ReadKeysAndCert(data []byte) (keys_and_cert KeysAndCert, remainder []byte, err error) {
 	data_len := len(data)
+	if data_len == 0 {
+		log.WithFields(log.Fields{
+			"at":     "ReadKeysAndCert",
+			"reason": "no data",
+		}).Error("error parsing keys and cert")
+		err = errors.New("error parsing KeysAndCert: no data")
+		return
+	}
 	if data_len < KEYS_AND_CERT_MIN_SIZE {
 		log.WithFields(log.Fields{
 			"at":           "ReadKeysAndCert",
@@ -183,6 +252,7 @@ func ReadKeysAndCert(data []byte) (keys_and_cert KeysAndCert, remainder []byte,
 			"reason":       "not enough data",
 		}).Error("error parsing keys and cert")
 		err = errors.New("error parsing KeysAndCert: data is smaller than minimum valid size")
+		DumpStructure("KeysAndCert", data, data_len)
 		return
 	}
 	keys_and_cert = KeysAndCert(data[:KEYS_AND_CERT_MIN_SIZE])
@@ -198,6 +268,41 @@ func ReadKeysAndCert(data []byte) (keys_and_cert KeysAndCert, remainder []byte,
 	} else {
 		keys_and_cert = append(keys_and_cert, data[KEYS_AND_CERT_MIN_SIZE:KEYS_AND_CERT_MIN_SIZE+cert_len]...)
 		remainder = data[KEYS_AND_CERT_MIN_SIZE+cert_len:]
+		// Keys too large for their fields spill into the Key Certificate,
+		// which must be long enough to hold them.
+		if cert_type, _ := cert.Type(); cert_type == CERT_KEY {
+			cert, _ = keys_and_cert.Certificate()
+			err = KeyCertificate(cert).ValidateKeyLengths()
+		}
+	}
+	return
+}
+
+//
+// Read a KeysAndCert like ReadKeysAndCert, but return an error if the Certificate
+// is of any type other than NULL or KEY instead of falling back to treating the
+// keys as ElGamal and DSA.
+//
+func ReadKeysAndCertStrict(data []byte) (keys_and_cert KeysAndCert, remainder []byte, err error) {
+	keys_and_cert, remainder, err = ReadKeysAndCert(data)
+	if err != nil {
+		return
+	}
+	cert, err := keys_and_cert.Certificate()
+	if err != nil {
+		return
+	}
+	cert_type, err := cert.Type()
+	if err != nil {
+		return
+	}
+	if cert_type != CERT_NULL && cert_type != CERT_KEY {
+		log.WithFields(log.Fields{
+			"at":        "ReadKeysAndCertStrict",
+			"cert_type": cert_type,
+			"reason":    "unsupported certificate type",
+		}).Error("error parsing keys and cert")
+		err = errors.New("error parsing KeysAndCert: unsupported certificate type")
 	}
 	return
 }
@@ -164,7 +164,18 @@ func TestSigningPublicKeyWithKeyCertificate(t *testing.T) {
 
 	signing_pub_key, err := keys_and_cert.SigningPublicKey()
 	assert.Nil(err)
-	assert.Equal(len(signing_pub_key_data), signing_pub_key.Len())
+	assert.Equal(KEYCERT_SIGN_P256_SIZE, signing_pub_key.Len())
+}
+
+func TestReadKeysAndCertWithNoData(t *testing.T) {
+	assert := assert.New(t)
+
+	keys_and_cert, remainder, err := ReadKeysAndCert([]byte{})
+	assert.Nil(keys_and_cert)
+	assert.Nil(remainder)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing KeysAndCert: no data", err.Error())
+	}
 }
 
 func TestReadKeysAndCertWithMissingData(t *testing.T) {
@@ -287,3 +298,121 @@ func TestReadKeysAndCertWithValidDataWithoutCertificateAndRemainder(t *testing.T
 	_, err = keys_and_cert.Certificate()
 	assert.Nil(err, "keys_and_cert.Certificate() returned error with valid data not containing certificate")
 }
+
+func TestKeysAndCertStringAbbreviatesKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, 128+256)
+	data = append(data, []byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x00}...)
+	str := KeysAndCert(data).String()
+
+	assert.Contains(str, "public_key: 00000000...(256 bytes)")
+	assert.Contains(str, "signing_key: 00000000...(128 bytes)")
+	assert.Contains(str, "type: KEY")
+}
+
+func TestReadKeysAndCertStrictRejectsHashCashCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, 128+256)
+	data = append(data, []byte{CERT_HASHCASH, 0x00, 0x01, 0x61}...)
+	_, _, err := ReadKeysAndCertStrict(data)
+
+	if assert.NotNil(err) {
+		assert.Equal("error parsing KeysAndCert: unsupported certificate type", err.Error())
+	}
+}
+
+func TestReadKeysAndCertStrictAcceptsKeyCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, 128+256)
+	data = append(data, []byte{CERT_KEY, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00}...)
+	data = append(data, 0x01)
+	keys_and_cert, remainder, err := ReadKeysAndCertStrict(data)
+
+	assert.Nil(err)
+	assert.Equal(KEYS_AND_CERT_MIN_SIZE+4, len(keys_and_cert))
+	assert.Equal([]byte{0x01}, remainder)
+}
+
+func TestReadKeysAndCertWithSpilledSigningKey(t *testing.T) {
+	assert := assert.New(t)
+
+	spk_data := make([]byte, KEYS_AND_CERT_SPK_SIZE)
+	for i := range spk_data {
+		spk_data[i] = 0x02
+	}
+	data := make([]byte, KEYS_AND_CERT_PUBKEY_SIZE)
+	data = append(data, spk_data...)
+	// P521 signing key with its last 4 bytes stored after the key types
+	data = append(data, []byte{0x05, 0x00, 0x08, 0x00, 0x03, 0x00, 0x00, 0x0a, 0x0b, 0x0c, 0x0d}...)
+	keys_and_cert, remainder, err := ReadKeysAndCert(data)
+	assert.Nil(err)
+	assert.Equal(0, len(remainder))
+
+	signing_pub_key, err := keys_and_cert.SigningPublicKey()
+	if assert.Nil(err) {
+		assert.Equal(KEYCERT_SIGN_P521_SIZE, signing_pub_key.Len())
+		key_bytes := signing_pub_key.Bytes()
+		assert.Equal(spk_data, key_bytes[:KEYS_AND_CERT_SPK_SIZE])
+		assert.Equal([]byte{0x0a, 0x0b, 0x0c, 0x0d}, key_bytes[KEYS_AND_CERT_SPK_SIZE:])
+	}
+}
+
+func TestReadKeysAndCertReportsMissingSpilledKeyData(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, KEYS_AND_CERT_DATA_SIZE)
+	data = append(data, []byte{0x05, 0x00, 0x04, 0x00, 0x03, 0x00, 0x00}...)
+	_, _, err := ReadKeysAndCert(data)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing key certificate: missing excess key data", err.Error())
+	}
+}
+
+func TestCertificatePayloadIncludesSpilledKeyData(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, KEYS_AND_CERT_DATA_SIZE)
+	data = append(data, []byte{0x05, 0x00, 0x08, 0x00, 0x03, 0x00, 0x00, 0x0a, 0x0b, 0x0c, 0x0d}...)
+	keys_and_cert, _, err := ReadKeysAndCert(data)
+	assert.Nil(err)
+
+	assert.Equal(
+		[]byte{0x00, 0x03, 0x00, 0x00, 0x0a, 0x0b, 0x0c, 0x0d},
+		keys_and_cert.CertificatePayload(),
+	)
+}
+
+func TestCertificatePayloadIsEmptyForNullCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, KEYS_AND_CERT_DATA_SIZE)
+	data = append(data, NewNullCertificate()...)
+	assert.Equal(0, len(KeysAndCert(data).CertificatePayload()))
+	assert.Nil(KeysAndCert(data[:KEYS_AND_CERT_DATA_SIZE]).CertificatePayload())
+}
+
+func TestParsingKeysAndCertDoesNotModifyInput(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, cert_data := range [][]byte{NewNullCertificate(), {CERT_HASHCASH, 0x00, 0x00}} {
+		data := make([]byte, KEYS_AND_CERT_DATA_SIZE)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		data = append(data, cert_data...)
+		original := append([]byte{}, data...)
+
+		keys_and_cert, _, err := ReadKeysAndCert(data)
+		assert.Nil(err)
+		pub_key, err := keys_and_cert.PublicKey()
+		assert.Nil(err)
+		_, err = keys_and_cert.SigningPublicKey()
+		assert.Nil(err)
+
+		assert.Equal(original, data, "parsing KeysAndCert modified the input buffer")
+		assert.Equal(original[:KEYS_AND_CERT_PUBKEY_SIZE], pub_key.Bytes())
+	}
+}
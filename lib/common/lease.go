@@ -29,6 +29,10 @@ end_date :: Date
             length -> 8 bytes
 */
 
+import (
+	"sort"
+)
+
 // Sizes or various components of a Lease
 const (
 	LEASE_SIZE           = 44
@@ -62,3 +66,15 @@ func (lease Lease) Date() (date Date) {
 	copy(date[:], lease[LEASE_HASH_SIZE+LEASE_TUNNEL_ID_SIZE:])
 	return
 }
+
+//
+// Return a copy of leases sorted by expiration, latest first, so the longest-lived Lease
+// comes first.  Leases expiring at the same time keep their order.
+//
+func SortLeasesByExpiration(leases []Lease) (sorted []Lease) {
+	sorted = append(sorted, leases...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date().Time().After(sorted[j].Date().Time())
+	})
+	return
+}
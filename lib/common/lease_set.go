@@ -81,9 +81,13 @@ signature :: Signature
 */
 
 import (
+	"encoding/json"
 	"errors"
+	"github.com/go-i2p/go-i2p/lib/common/base32"
 	"github.com/go-i2p/go-i2p/lib/crypto"
 	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
 )
 
 // Sizes of various structures in an I2P LeaseSet
@@ -93,6 +97,11 @@ const (
 	LEASE_SET_SIG_SIZE    = 40
 )
 
+// Most Leases a legacy LeaseSet may hold.  Although the count is stored in a single
+// byte, the specification caps it at 16.  LeaseSet2 is not capped in the same way
+// and must not be checked against this limit.
+const LEASE_SET_MAX_LEASES = 16
+
 type LeaseSet []byte
 
 //
@@ -125,6 +134,63 @@ func (lease_set LeaseSet) PublicKey() (public_key crypto.ElgPublicKey, err error
 	return
 }
 
+//
+// Return the encryption key as the concrete type given by the crypto type in the
+// Destination's Key Certificate, or as an ElgPublicKey for Destinations without one.
+//
+func (lease_set LeaseSet) EncryptionKey() (public_key crypto.PublicKey, err error) {
+	destination, err := lease_set.Destination()
+	if err != nil {
+		return
+	}
+	offset := len(destination)
+	data, err := lease_set.field(offset, offset+LEASE_SET_PUBKEY_SIZE, "(LeaseSet) EncryptionKey", "public key")
+	if err != nil {
+		return
+	}
+	cert, err := destination.Certificate()
+	if err != nil {
+		return
+	}
+	if cert_type, _ := cert.Type(); cert_type != CERT_KEY {
+		var elg_key crypto.ElgPublicKey
+		copy(elg_key[:], data)
+		public_key = elg_key
+		return
+	}
+	public_key, err = KeyCertificate(cert).ConstructPublicKey(data)
+	if err == nil && public_key == nil {
+		log.WithFields(log.Fields{
+			"at":     "(LeaseSet) EncryptionKey",
+			"reason": "unsupported encryption key type",
+		}).Error("error parsing public key")
+		err = errors.New("error parsing public key: unsupported encryption key type")
+	}
+	return
+}
+
+//
+// Encrypt data to the LeaseSet's Destination with ElGamal/AES, so payloads of any length
+// can be sent.  Only ElGamal encryption keys are supported: X25519 keys are used through
+// ECIES-X25519 key agreement, which is not implemented, and return an error.
+//
+func (lease_set LeaseSet) Encrypt(data []byte) (encrypted []byte, err error) {
+	public_key, err := lease_set.EncryptionKey()
+	if err != nil {
+		return
+	}
+	elg_key, ok := public_key.(crypto.ElgPublicKey)
+	if !ok {
+		log.WithFields(log.Fields{
+			"at":     "(LeaseSet) Encrypt",
+			"reason": "only ElGamal encryption keys are supported",
+		}).Error("error encrypting to lease set")
+		err = errors.New("error encrypting to lease set: only ElGamal encryption keys are supported")
+		return
+	}
+	return crypto.ElgAESEncrypt(elg_key, data)
+}
+
 //
 // Return the SigningPublicKey, as specified in the LeaseSet's Destination's Key Certificate if
 // present, or a legacy DSA key.
@@ -143,22 +209,15 @@ func (lease_set LeaseSet) SigningKey() (signing_public_key crypto.SigningPublicK
 	if err != nil {
 		return
 	}
-	lease_set_len := len(lease_set)
-	if lease_set_len < offset+LEASE_SET_SPK_SIZE {
-		log.WithFields(log.Fields{
-			"at":           "(LeaseSet) SigningKey",
-			"data_len":     lease_set_len,
-			"required_len": offset + LEASE_SET_SPK_SIZE,
-			"reason":       "not enough data",
-		}).Error("error parsing signing public key")
-		err = errors.New("error parsing signing public key: not enough data")
+	spk_data, err := lease_set.field(offset, offset+LEASE_SET_SPK_SIZE, "(LeaseSet) SigningKey", "signing public key")
+	if err != nil {
 		return
 	}
 	if cert_len == 0 {
 		// No Certificate is present, return the LEASE_SET_SPK_SIZE byte
 		// SigningPublicKey space as legacy DSA SHA1 SigningPublicKey.
 		var dsa_pk crypto.DSAPublicKey
-		copy(dsa_pk[:], lease_set[offset:offset+LEASE_SET_SPK_SIZE])
+		copy(dsa_pk[:], spk_data)
 		signing_public_key = dsa_pk
 	} else {
 		// A Certificate is present in this LeaseSet's Destination
@@ -167,14 +226,12 @@ func (lease_set LeaseSet) SigningKey() (signing_public_key crypto.SigningPublicK
 			// This LeaseSet's Destination's Certificate is a Key Certificate,
 			// create the signing publickey key using any data that might be
 			// contained in the key certificate.
-			signing_public_key, err = KeyCertificate(cert).ConstructSigningPublicKey(
-				lease_set[offset : offset+LEASE_SET_SPK_SIZE],
-			)
+			signing_public_key, err = KeyCertificate(cert).ConstructSigningPublicKey(spk_data)
 		} else {
 			// No Certificate is present, return the LEASE_SET_SPK_SIZE byte
 			// SigningPublicKey space as legacy DSA SHA1 SigningPublicKey.
 			var dsa_pk crypto.DSAPublicKey
-			copy(dsa_pk[:], lease_set[offset:offset+LEASE_SET_SPK_SIZE])
+			copy(dsa_pk[:], spk_data)
 			signing_public_key = dsa_pk
 		}
 
@@ -201,8 +258,11 @@ func (lease_set LeaseSet) LeaseCount() (count int, err error) {
 		err = errors.New("error parsing lease count: not enough data")
 		return
 	}
-	count = Integer([]byte{remainder[LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE]})
-	if count > 16 {
+	count, _, err = NewInteger(remainder[LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE:], 1)
+	if err != nil {
+		return
+	}
+	if count > LEASE_SET_MAX_LEASES {
 		log.WithFields(log.Fields{
 			"at":          "(LeaseSet) LeaseCount",
 			"lease_count": count,
@@ -229,24 +289,33 @@ func (lease_set LeaseSet) Leases() (leases []Lease, err error) {
 	for i := 0; i < count; i++ {
 		start := offset + (i * LEASE_SIZE)
 		end := start + LEASE_SIZE
-		lease_set_len := len(lease_set)
-		if lease_set_len < end {
-			log.WithFields(log.Fields{
-				"at":           "(LeaseSet) Leases",
-				"data_len":     lease_set_len,
-				"required_len": end,
-				"reason":       "some leases missing",
-			}).Error("error parsnig lease set")
+		var lease_data []byte
+		lease_data, err = lease_set.field(start, end, "(LeaseSet) Leases", "lease set")
+		if err != nil {
 			err = errors.New("error parsing lease set: some leases missing")
 			return
 		}
 		var lease Lease
-		copy(lease[:], lease_set[start:end])
+		copy(lease[:], lease_data)
 		leases = append(leases, lease)
 	}
 	return
 }
 
+//
+// Return the Hash of the tunnel gateway of each Lease in this LeaseSet, in Lease order.
+//
+func (lease_set LeaseSet) GatewayHashes() (hashes []Hash, err error) {
+	leases, err := lease_set.Leases()
+	if err != nil {
+		return
+	}
+	for _, lease := range leases {
+		hashes = append(hashes, lease.TunnelGateway())
+	}
+	return
+}
+
 //
 // Return the Signature data for the LeaseSet, as specified in the Destination's
 // Key Certificate if present or the 40 bytes following the Leases.
@@ -276,39 +345,249 @@ func (lease_set LeaseSet) Signature() (signature Signature, err error) {
 	} else {
 		end = start + LEASE_SET_SIG_SIZE
 	}
+	data, err := lease_set.field(start, end, "(LeaseSet) Signature", "signature")
+	if err != nil {
+		return
+	}
+	signature = Signature(data)
+	return
+}
+
+//
+// Return the bytes of the LeaseSet from start to end, or an error naming the field
+// being read if the LeaseSet is too short to contain them.
+//
+func (lease_set LeaseSet) field(start, end int, at, name string) (data []byte, err error) {
 	lease_set_len := len(lease_set)
-	if lease_set_len < end {
+	if start < 0 || end < start || lease_set_len < end {
 		log.WithFields(log.Fields{
-			"at":           "(LeaseSet) Signature",
+			"at":           at,
 			"data_len":     lease_set_len,
 			"required_len": end,
 			"reason":       "not enough data",
-		}).Error("error parsing signatre")
-		err = errors.New("error parsing signature: not enough data")
+		}).Error("error parsing " + name)
+		err = errors.New("error parsing " + name + ": not enough data")
 		return
 	}
-	signature = []byte(lease_set[start:end])
+	data = lease_set[start:end]
 	return
 }
 
 //
+// Verify the LeaseSet's Signature with its SigningKey, returning nil if the signature is valid.
 //
+func (lease_set LeaseSet) Verify() (err error) {
+	data, err := lease_set.SignableData()
+	if err != nil {
+		return
+	}
+	signature, err := lease_set.Signature()
+	if err != nil {
+		return
+	}
+	spk, err := lease_set.SigningKey()
+	if err != nil {
+		return
+	}
+	verifier, err := spk.NewVerifier()
+	if err != nil {
+		return
+	}
+	err = verifier.Verify(data, signature)
+	return
+}
+
+//
+// Return the bytes of the LeaseSet covered by its Signature, which is everything from
+// the Destination through the last Lease.
 //
-func (lease_set LeaseSet) Verify() error {
-	//data_end := len(destination) +
-	//	LEASE_SET_PUBKEY_SIZE +
-	//	LEASE_SET_SPK_SIZE +
-	//	1 +
-	//	(44 * lease_set.LeaseCount())
-	//data := lease_set[:data_end]
-	//spk, _ := lease_set.
-	//	Destination().
-	//	SigningPublicKey()
-	//verifier, err := spk.NewVerifier()
-	//if err != nil {
-	//	return err
-	//}
-	return nil // verifier.Verify(data, lease_set.Signature())
+func (lease_set LeaseSet) SignableData() (data []byte, err error) {
+	destination, err := lease_set.Destination()
+	if err != nil {
+		return
+	}
+	lease_count, err := lease_set.LeaseCount()
+	if err != nil {
+		return
+	}
+	data_end := len(destination) +
+		LEASE_SET_PUBKEY_SIZE +
+		LEASE_SET_SPK_SIZE +
+		1 +
+		(LEASE_SIZE * lease_count)
+	data, err = lease_set.field(0, data_end, "(LeaseSet) SignableData", "lease set")
+	return
+}
+
+//
+// Return a readable JSON object describing this LeaseSet for tooling, with the base32
+// address of its Destination, the Signing Key Type of its Signature and each Lease's
+// tunnel gateway in base32, tunnel ID and expiration.  Key material is not included.
+//
+func (lease_set LeaseSet) MarshalJSON() (data []byte, err error) {
+	destination, err := lease_set.Destination()
+	if err != nil {
+		return
+	}
+	cert, err := destination.Certificate()
+	if err != nil {
+		return
+	}
+	signature_type := KEYCERT_SIGN_DSA_SHA1
+	if cert_type, _ := cert.Type(); cert_type == CERT_KEY {
+		signature_type, err = KeyCertificate(cert).SigningPublicKeyType()
+		if err != nil {
+			return
+		}
+	}
+	leases, err := lease_set.Leases()
+	if err != nil {
+		return
+	}
+	object := leaseSetJSON{
+		Destination:   destination.Base32Address(),
+		SignatureType: signature_type,
+		LeaseCount:    len(leases),
+		Leases:        []leaseJSON{},
+	}
+	for _, lease := range leases {
+		gateway := lease.TunnelGateway()
+		object.Leases = append(object.Leases, leaseJSON{
+			TunnelGateway: strings.TrimRight(base32.EncodeToString(gateway[:]), "="),
+			TunnelID:      lease.TunnelID(),
+			Expiration:    lease.Date().Time().UTC(),
+		})
+	}
+	data, err = json.Marshal(object)
+	return
+}
+
+// The JSON form of a LeaseSet written by MarshalJSON.
+type leaseSetJSON struct {
+	Destination   string      `json:"destination"`
+	SignatureType int         `json:"signature_type"`
+	LeaseCount    int         `json:"lease_count"`
+	Leases        []leaseJSON `json:"leases"`
+}
+
+// The JSON form of a Lease inside a leaseSetJSON.
+type leaseJSON struct {
+	TunnelGateway string    `json:"tunnel_gateway"`
+	TunnelID      uint32    `json:"tunnel_id"`
+	Expiration    time.Time `json:"expiration"`
+}
+
+//
+// Read a LeaseSet from a slice of bytes, returning any extra data on the end of the slice
+// and any errors if a valid LeaseSet could not be read.  The lease count is checked
+// against limits before the Leases are read.
+//
+func ReadLeaseSet(data []byte, limits ParseLimits) (lease_set LeaseSet, remainder []byte, err error) {
+	destination, remaining, err := ReadKeysAndCert(data)
+	if err != nil {
+		return
+	}
+	if len(remaining) < LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE+1 {
+		log.WithFields(log.Fields{
+			"at":           "ReadLeaseSet",
+			"data_len":     len(remaining),
+			"required_len": LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE + 1,
+			"reason":       "not enough data",
+		}).Error("error parsing lease count")
+		err = errors.New("error parsing lease count: not enough data")
+		return
+	}
+	count, _, err := NewInteger(remaining[LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE:], 1)
+	if err != nil {
+		return
+	}
+	err = limits.check(count, limits.maxLeases(), "ReadLeaseSet", "lease set", "too many leases")
+	if err != nil {
+		return
+	}
+	end := len(destination) +
+		LEASE_SET_PUBKEY_SIZE +
+		LEASE_SET_SPK_SIZE +
+		1 +
+		(LEASE_SIZE * count) +
+		destination.signatureSize()
+	if len(data) < end {
+		log.WithFields(log.Fields{
+			"at":           "ReadLeaseSet",
+			"data_len":     len(data),
+			"required_len": end,
+			"reason":       "not enough data",
+		}).Error("error parsing lease set")
+		err = errors.New("error parsing lease set: not enough data")
+		return
+	}
+	lease_set = LeaseSet(data[:end])
+	remainder = data[end:]
+	return
+}
+
+//
+// Assemble a LeaseSet from its components and sign it with signer.  An encryption key
+// shorter than the LEASE_SET_PUBKEY_SIZE byte field, such as X25519, is stored at its
+// start followed by padding, and the SigningPublicKey is right aligned in the
+// LEASE_SET_SPK_SIZE byte field, as expected by EncryptionKey() and SigningKey().
+//
+func NewLeaseSet(
+	destination Destination,
+	encryption_key crypto.PublicKey,
+	signing_key crypto.SigningPublicKey,
+	leases []Lease,
+	signer crypto.Signer,
+) (lease_set LeaseSet, err error) {
+	_, _, err = ReadDestination(destination)
+	if err != nil {
+		return
+	}
+	if encryption_key.Len() > LEASE_SET_PUBKEY_SIZE {
+		log.WithFields(log.Fields{
+			"at":      "NewLeaseSet",
+			"key_len": encryption_key.Len(),
+			"max_len": LEASE_SET_PUBKEY_SIZE,
+			"reason":  "encryption key is too large",
+		}).Error("error creating lease set")
+		err = errors.New("error creating lease set: encryption key is too large")
+		return
+	}
+	if signing_key.Len() > LEASE_SET_SPK_SIZE {
+		log.WithFields(log.Fields{
+			"at":      "NewLeaseSet",
+			"key_len": signing_key.Len(),
+			"max_len": LEASE_SET_SPK_SIZE,
+			"reason":  "signing key is too large",
+		}).Error("error creating lease set")
+		err = errors.New("error creating lease set: signing key is too large")
+		return
+	}
+	if len(leases) > LEASE_SET_MAX_LEASES {
+		log.WithFields(log.Fields{
+			"at":          "NewLeaseSet",
+			"lease_count": len(leases),
+			"reason":      "more than 16 leases",
+		}).Error("error creating lease set")
+		err = errors.New("error creating lease set: more than 16 leases")
+		return
+	}
+	data := make([]byte, 0, len(destination)+LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE+1+LEASE_SIZE*len(leases))
+	data = append(data, destination...)
+	data = append(data, encryption_key.Bytes()...)
+	data = append(data, make([]byte, LEASE_SET_PUBKEY_SIZE-encryption_key.Len())...)
+	data = append(data, make([]byte, LEASE_SET_SPK_SIZE-signing_key.Len())...)
+	data = append(data, signing_key.Bytes()...)
+	data = append(data, byte(len(leases)))
+	for _, lease := range leases {
+		data = append(data, lease[:]...)
+	}
+	signature, err := signer.Sign(data)
+	if err != nil {
+		return
+	}
+	lease_set = LeaseSet(append(data, signature...))
+	return
 }
 
 //
@@ -329,6 +608,19 @@ func (lease_set LeaseSet) NewestExpiration() (oldest Date, err error) {
 	return
 }
 
+//
+// Return true if every Lease in the LeaseSet has expired by now, leaving no tunnel to reach
+// the Destination through.  A LeaseSet without Leases, or whose Leases cannot be read, is
+// expired.
+//
+func (lease_set LeaseSet) IsExpired(now time.Time) bool {
+	newest, err := lease_set.NewestExpiration()
+	if err != nil {
+		return true
+	}
+	return !newest.Time().After(now)
+}
+
 //
 // Return the oldest date from all the Leases in the LeaseSet.
 //
@@ -2,8 +2,14 @@ package common
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/go-i2p/go-i2p/lib/crypto"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp/elgamal"
 	"testing"
+	"time"
 )
 
 func buildDestination() RouterIdentity {
@@ -69,9 +75,7 @@ func TestDestinationIsCorrect(t *testing.T) {
 	lease_set := buildFullLeaseSet(1)
 	dest, err := lease_set.Destination()
 	assert.Nil(err)
-	dest_cert, err := dest.Certificate()
-	assert.Nil(err)
-	cert_type, err := dest_cert.Type()
+	cert_type, err := dest.CertificateType()
 	assert.Nil(err)
 	assert.Equal(CERT_KEY, cert_type)
 }
@@ -98,7 +102,7 @@ func TestSigningKeyIsCorrect(t *testing.T) {
 	lease_set := buildFullLeaseSet(1)
 	sk, err := lease_set.SigningKey()
 	if assert.Nil(err) {
-		assert.Equal(128, sk.Len())
+		assert.Equal(KEYCERT_SIGN_P256_SIZE, sk.Len())
 	}
 }
 
@@ -201,3 +205,360 @@ func TestOldestExpirationIsCorrect(t *testing.T) {
 		latest,
 	)
 }
+
+func TestAccessorsDoNotPanicOnTruncatedLeaseSet(t *testing.T) {
+	assert := assert.New(t)
+
+	full := buildFullLeaseSet(2)
+	destination_len := len(buildDestination())
+	boundaries := []int{
+		0,
+		destination_len - 1,
+		destination_len + LEASE_SET_PUBKEY_SIZE - 1,
+		destination_len + LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE - 1,
+		destination_len + LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE + 1 + LEASE_SIZE - 1,
+		destination_len + LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE + 1 + 2*LEASE_SIZE + 1,
+	}
+	for _, boundary := range boundaries {
+		lease_set := LeaseSet(full[:boundary])
+		assert.NotPanics(func() {
+			lease_set.Destination()
+			lease_set.PublicKey()
+			lease_set.SigningKey()
+			lease_set.LeaseCount()
+			lease_set.Leases()
+			lease_set.Signature()
+		}, "LeaseSet accessors panicked when truncated at %d", boundary)
+		_, err := lease_set.Signature()
+		assert.NotNil(err, "LeaseSet.Signature() did not report truncation at %d", boundary)
+	}
+}
+
+func TestSigningKeyReportsTruncatedLeaseSet(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(1)
+	lease_set = lease_set[:len(buildDestination())+LEASE_SET_PUBKEY_SIZE+1]
+	_, err := lease_set.SigningKey()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing signing public key: not enough data", err.Error())
+	}
+}
+
+func TestSigningKeyDoesNotPanicWithShortP521Certificate(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set_data := make([]byte, 128+256)
+	lease_set_data = append(lease_set_data, []byte{0x05, 0x00, 0x04, 0x00, 0x03, 0x00, 0x03}...)
+	lease_set_data = append(lease_set_data, buildPublicKey()...)
+	lease_set_data = append(lease_set_data, buildSigningKey()...)
+	lease_set := LeaseSet(lease_set_data)
+
+	assert.NotPanics(func() {
+		_, err := lease_set.SigningKey()
+		assert.NotNil(err)
+	})
+}
+
+func buildSignedLeaseSet(t *testing.T, n int) LeaseSet {
+	var signing_private_key crypto.DSAPrivateKey
+	signing_private_key, err := signing_private_key.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing_public_key, err := signing_private_key.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := signing_private_key.NewSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination_data := make([]byte, KEYS_AND_CERT_PUBKEY_SIZE)
+	destination_data = append(destination_data, signing_public_key[:]...)
+	destination_data = append(destination_data, NewNullCertificate()...)
+	var encryption_key crypto.ElgPublicKey
+	copy(encryption_key[:], buildPublicKey())
+	var leases []Lease
+	lease_data := buildLease(n)
+	for i := 0; i < n; i++ {
+		var lease Lease
+		copy(lease[:], lease_data[i*LEASE_SIZE:])
+		leases = append(leases, lease)
+	}
+	lease_set, err := NewLeaseSet(Destination(destination_data), encryption_key, signing_public_key, leases, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lease_set
+}
+
+func TestNewLeaseSetBuildsVerifiableLeaseSet(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildSignedLeaseSet(t, 2)
+	count, err := lease_set.LeaseCount()
+	assert.Nil(err)
+	assert.Equal(2, count)
+	leases, err := lease_set.Leases()
+	if assert.Nil(err) && assert.Equal(2, len(leases)) {
+		assert.Equal(byte(0x01), leases[1][0])
+	}
+	signature, err := lease_set.Signature()
+	assert.Nil(err)
+	assert.Equal(LEASE_SET_SIG_SIZE, len(signature))
+	assert.Nil(lease_set.Verify(), "LeaseSet.Verify() rejected a LeaseSet built by NewLeaseSet()")
+}
+
+func TestVerifyRejectsTamperedLeaseSet(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildSignedLeaseSet(t, 2)
+	lease_set[KEYS_AND_CERT_MIN_SIZE] ^= 0xff
+	assert.NotNil(lease_set.Verify(), "LeaseSet.Verify() accepted a tampered LeaseSet")
+}
+
+func TestNewLeaseSetRejectsTooManyLeases(t *testing.T) {
+	assert := assert.New(t)
+
+	var encryption_key crypto.ElgPublicKey
+	var signing_key crypto.DSAPublicKey
+	_, err := NewLeaseSet(Destination(buildDestination()), encryption_key, signing_key, make([]Lease, 17), nil)
+	if assert.NotNil(err) {
+		assert.Equal("error creating lease set: more than 16 leases", err.Error())
+	}
+}
+
+func TestSignableDataExcludesSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(3)
+	data, err := lease_set.SignableData()
+	assert.Nil(err)
+	signature, err := lease_set.Signature()
+	assert.Nil(err)
+	assert.Equal(len(lease_set)-len(signature), len(data))
+}
+
+func TestLeaseCountAtMaximum(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(LEASE_SET_MAX_LEASES)
+	count, err := lease_set.LeaseCount()
+	assert.Nil(err)
+	assert.Equal(LEASE_SET_MAX_LEASES, count)
+	leases, err := lease_set.Leases()
+	assert.Nil(err)
+	assert.Equal(LEASE_SET_MAX_LEASES, len(leases))
+}
+
+func TestEncryptionKeyIsElGamal(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(1)
+	public_key, err := lease_set.EncryptionKey()
+	if assert.Nil(err) {
+		elg_key, ok := public_key.(crypto.ElgPublicKey)
+		assert.True(ok, "EncryptionKey() did not return an ElgPublicKey")
+		assert.Equal(buildPublicKey(), elg_key[:])
+	}
+
+	public_key, err = buildSignedLeaseSet(t, 1).EncryptionKey()
+	if assert.Nil(err) {
+		_, ok := public_key.(crypto.ElgPublicKey)
+		assert.True(ok, "EncryptionKey() did not return an ElgPublicKey for a NULL certificate")
+	}
+}
+
+func TestGatewayHashesReturnsEachLeaseGateway(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(3)
+	hashes, err := lease_set.GatewayHashes()
+	assert.Nil(err)
+	if assert.Equal(3, len(hashes)) {
+		for i, hash := range hashes {
+			assert.Equal(byte(i), hash[0])
+		}
+		assert.NotEqual(hashes[0], hashes[1])
+		assert.NotEqual(hashes[1], hashes[2])
+		assert.NotEqual(hashes[0], hashes[2])
+	}
+}
+
+func TestEncryptRoundTripsWithDestinationKey(t *testing.T) {
+	assert := assert.New(t)
+
+	k := new(elgamal.PrivateKey)
+	err := crypto.ElgamalGenerate(k, rand.Reader)
+	assert.Nil(err)
+	var public_key crypto.ElgPublicKey
+	var private_key crypto.ElgPrivateKey
+	y := k.Y.Bytes()
+	copy(public_key[len(public_key)-len(y):], y)
+	x := k.X.Bytes()
+	copy(private_key[len(private_key)-len(x):], x)
+
+	lease_set := buildFullLeaseSet(1)
+	copy(lease_set[len(buildDestination()):], public_key[:])
+	payload := []byte("hello i2p")
+	encrypted, err := lease_set.Encrypt(payload)
+	assert.Nil(err)
+	decrypted, err := crypto.ElgAESDecrypt(private_key, encrypted)
+	assert.Nil(err)
+	assert.Equal(payload, decrypted)
+}
+
+// build a LeaseSet signed with Ed25519 whose Destination has a random X25519 encryption key
+func buildX25519LeaseSet(t *testing.T) (LeaseSet, crypto.X25519PublicKey) {
+	var encryption_private_key crypto.X25519PrivateKey
+	encryption_private_key, err := encryption_private_key.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryption_key, err := encryption_private_key.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing_private_key := crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	signing_key, err := signing_private_key.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := signing_private_key.NewSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination_data := make([]byte, KEYS_AND_CERT_PUBKEY_SIZE)
+	copy(destination_data, encryption_key[:])
+	destination_data = append(destination_data, make([]byte, KEYS_AND_CERT_SPK_SIZE-signing_key.Len())...)
+	destination_data = append(destination_data, signing_key.Bytes()...)
+	destination_data = append(destination_data, newKeyCertificate(KEYCERT_SIGN_ED25519, KEYCERT_CRYPTO_X25519)...)
+	lease_set, err := NewLeaseSet(Destination(destination_data), encryption_key, signing_key, nil, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lease_set, encryption_key
+}
+
+func TestNewLeaseSetStoresX25519Key(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set, encryption_key := buildX25519LeaseSet(t)
+	public_key, err := lease_set.EncryptionKey()
+	assert.Nil(err)
+	assert.Equal(encryption_key, public_key)
+	assert.Nil(lease_set.Verify())
+}
+
+func TestEncryptReportsUnsupportedX25519(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set, _ := buildX25519LeaseSet(t)
+	encrypted, err := lease_set.Encrypt([]byte("hello i2p"))
+	assert.Nil(encrypted)
+	if assert.NotNil(err) {
+		assert.Equal("error encrypting to lease set: only ElGamal encryption keys are supported", err.Error())
+	}
+}
+
+func TestIsExpiredWithAllLeasesExpired(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(2)
+	assert.True(lease_set.IsExpired(time.Unix(1600000000, 0)))
+	assert.False(lease_set.IsExpired(time.Unix(0, 0)))
+}
+
+func TestIsExpiredWithOneLeaseValid(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1600000000, 0)
+	lease_set := buildFullLeaseSet(2)
+	second_lease := len(buildDestination()) + LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE + 1 + LEASE_SIZE
+	expiration := NewDate(now.Add(time.Minute))
+	copy(lease_set[second_lease+LEASE_SIZE-len(expiration):], expiration[:])
+	assert.False(lease_set.IsExpired(now))
+	assert.True(lease_set.IsExpired(now.Add(time.Minute)))
+}
+
+func TestIsExpiredWithNoLeases(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(buildFullLeaseSet(0).IsExpired(time.Unix(0, 0)))
+}
+
+func TestReadLeaseSetReturnsRemainder(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(2)
+	data := append(append([]byte{}, lease_set...), 0x01)
+	read, remainder, err := ReadLeaseSet(data, DefaultParseLimits())
+	assert.Nil(err)
+	assert.Equal(lease_set, read)
+	assert.Equal([]byte{0x01}, remainder)
+}
+
+func TestReadLeaseSetRejectsTooManyLeases(t *testing.T) {
+	assert := assert.New(t)
+
+	limits := DefaultParseLimits()
+	limits.MaxLeases = 1
+	_, _, err := ReadLeaseSet(buildFullLeaseSet(2), limits)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing lease set: too many leases", err.Error())
+	}
+}
+
+func TestReadLeaseSetTreatsZeroLimitsAsDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(LEASE_SET_MAX_LEASES)
+	read, remainder, err := ReadLeaseSet(lease_set, ParseLimits{})
+	assert.Nil(err)
+	assert.Equal(lease_set, read)
+	assert.Equal(0, len(remainder))
+}
+
+func TestReadLeaseSetReportsTruncation(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(2)
+	_, _, err := ReadLeaseSet(lease_set[:len(lease_set)-1], DefaultParseLimits())
+	if assert.NotNil(err) {
+		assert.Equal("error parsing lease set: not enough data", err.Error())
+	}
+}
+
+func TestLeaseSetMarshalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	lease_set := buildFullLeaseSet(3)
+	data, err := json.Marshal(lease_set)
+	assert.Nil(err)
+	destination, err := lease_set.Destination()
+	assert.Nil(err)
+
+	var decoded struct {
+		Destination   string `json:"destination"`
+		SignatureType int    `json:"signature_type"`
+		LeaseCount    int    `json:"lease_count"`
+		Leases        []struct {
+			TunnelGateway string    `json:"tunnel_gateway"`
+			TunnelID      uint32    `json:"tunnel_id"`
+			Expiration    time.Time `json:"expiration"`
+		} `json:"leases"`
+	}
+	assert.Nil(json.Unmarshal(data, &decoded))
+	assert.Equal(destination.Base32Address(), decoded.Destination)
+	assert.Equal(KEYCERT_SIGN_P256, decoded.SignatureType)
+	assert.Equal(3, decoded.LeaseCount)
+	if assert.Equal(3, len(decoded.Leases)) {
+		leases, err := lease_set.Leases()
+		assert.Nil(err)
+		for i, lease := range leases {
+			assert.Equal(lease.TunnelID(), decoded.Leases[i].TunnelID)
+			assert.True(lease.Date().Time().Equal(decoded.Leases[i].Expiration))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// build a Lease through the gateway identified by id, expiring at expiration
+func buildLeaseExpiring(id byte, expiration time.Time) (lease Lease) {
+	lease[0] = id
+	date := NewDate(expiration)
+	copy(lease[LEASE_HASH_SIZE+LEASE_TUNNEL_ID_SIZE:], date[:])
+	return
+}
+
+func TestSortLeasesByExpiration(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1600000000, 0)
+	leases := []Lease{
+		buildLeaseExpiring(0, now.Add(time.Minute)),
+		buildLeaseExpiring(1, now.Add(time.Hour)),
+		buildLeaseExpiring(2, now),
+	}
+	sorted := SortLeasesByExpiration(leases)
+	if assert.Equal(3, len(sorted)) {
+		assert.Equal(byte(1), sorted[0][0])
+		assert.Equal(byte(0), sorted[1][0])
+		assert.Equal(byte(2), sorted[2][0])
+	}
+	assert.Equal(byte(0), leases[0][0], "SortLeasesByExpiration() reordered its input")
+}
@@ -30,6 +30,8 @@ import (
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"sort"
+	"strings"
+	"time"
 )
 
 type Mapping []byte
@@ -37,21 +39,55 @@ type Mapping []byte
 // Parsed key-values pairs inside a Mapping.
 type MappingValues [][2]String
 
+// Returned by ParseMappingLimited when a Mapping holds more key-value pairs than allowed
+var ErrTooManyMappingPairs = errors.New("error parsing mapping: too many pairs")
+
 //
 // Returns the values contained in a Mapping in the form of a MappingValues.
 //
 func (mapping Mapping) Values() (map_values MappingValues, errs []error) {
+	return mapping.values("(Mapping) Values", -1)
+}
+
+//
+// Parse a Mapping from data, stopping with ErrTooManyMappingPairs once more than
+// max_pairs key-value pairs have been read so a pathological Mapping cannot force
+// unbounded parsing.
+//
+func ParseMappingLimited(data []byte, max_pairs int) (map_values MappingValues, errs []error) {
+	return Mapping(data).values("ParseMappingLimited", max_pairs)
+}
+
+//
+// Parse the key-value pairs of the Mapping, reading at most max_pairs of them unless
+// max_pairs is negative.  at names the caller in log messages.
+//
+func (mapping Mapping) values(at string, max_pairs int) (map_values MappingValues, errs []error) {
 	var str String
 	var remainder = mapping
 	var err error
 
-	length := Integer(remainder[:2])
+	if len(mapping) < 2 {
+		log.WithFields(log.Fields{
+			"at":           at,
+			"data_len":     len(mapping),
+			"required_len": 2,
+			"reason":       "not enough data",
+		}).Error("error parsing mapping")
+		errs = append(errs, errors.New("error parsing mapping: not enough data"))
+		return
+	}
+	length, _, err := NewInteger(remainder, 2)
+	if err != nil {
+		errs = append(errs, err)
+		return
+	}
 	inferred_length := length + 2
 	remainder = remainder[2:]
 	mapping_len := len(mapping)
 	if mapping_len > inferred_length {
 		log.WithFields(log.Fields{
-			"at":                    "(Mapping) Values",
+			"at":                    at,
 			"mappnig_bytes_length":  mapping_len,
 			"mapping_length_field":  length,
 			"expected_bytes_length": inferred_length,
@@ -60,7 +96,7 @@ func (mapping Mapping) Values() (map_values MappingValues, errs []error) {
 		errs = append(errs, errors.New("warning parsing mapping: data exists beyond length of mapping"))
 	} else if inferred_length > mapping_len {
 		log.WithFields(log.Fields{
-			"at":                    "(Mapping) Values",
+			"at":                    at,
 			"mappnig_bytes_length":  mapping_len,
 			"mapping_length_field":  length,
 			"expected_bytes_length": inferred_length,
@@ -82,7 +118,7 @@ func (mapping Mapping) Values() (map_values MappingValues, errs []error) {
 		}
 		if !beginsWith(remainder, 0x3d) {
 			log.WithFields(log.Fields{
-				"at":     "(Mapping) Values",
+				"at":     at,
 				"reason": "expected =",
 			}).Warn("mapping format violation")
 			errs = append(errs, errors.New("mapping format violation, expected ="))
@@ -102,7 +138,7 @@ func (mapping Mapping) Values() (map_values MappingValues, errs []error) {
 		}
 		if !beginsWith(remainder, 0x3b) {
 			log.WithFields(log.Fields{
-				"at":     "(Mapping) Values",
+				"at":     at,
 				"reason": "expected ;",
 			}).Warn("mapping format violation")
 			errs = append(errs, errors.New("mapping format violation, expected ;"))
@@ -111,6 +147,15 @@ func (mapping Mapping) Values() (map_values MappingValues, errs []error) {
 		remainder = remainder[1:]
 
 		// Append the key-value pair and break if there is no more data to read
+		if max_pairs >= 0 && len(map_values) == max_pairs {
+			log.WithFields(log.Fields{
+				"at":        at,
+				"max_pairs": max_pairs,
+				"reason":    "too many pairs",
+			}).Warn("mapping format violation")
+			errs = append(errs, ErrTooManyMappingPairs)
+			return
+		}
 		map_values = append(map_values, [2]String{key_str, val_str})
 		if len(remainder) == 0 {
 			break
@@ -119,6 +164,80 @@ func (mapping Mapping) Values() (map_values MappingValues, errs []error) {
 	return
 }
 
+//
+// Return the value stored under key in this Mapping and whether the key was present.
+//
+func (mapping Mapping) Get(key string) (value string, present bool) {
+	if len(mapping) < 2 {
+		return
+	}
+	values, _ := mapping.Values()
+	for _, pair := range values {
+		pair_key, _ := pair[0].Data()
+		if pair_key == key {
+			value, _ = pair[1].Data()
+			present = true
+			return
+		}
+	}
+	return
+}
+
+//
+// Return the value stored under key as a bool, accepting true, yes and 1 or false, no
+// and 0 in any case.  ok is false if the key is missing or its value is none of these.
+//
+func (mapping Mapping) GetBool(key string) (value bool, ok bool) {
+	str, present := mapping.Get(key)
+	if !present {
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "true", "yes", "1":
+		value, ok = true, true
+	case "false", "no", "0":
+		value, ok = false, true
+	}
+	return
+}
+
+//
+// Return the value stored under key as a time.Duration written as time.ParseDuration
+// expects, such as "30s" or "1h30m".  ok is false if the key is missing or its value
+// cannot be parsed.
+//
+func (mapping Mapping) GetDuration(key string) (value time.Duration, ok bool) {
+	str, present := mapping.Get(key)
+	if !present {
+		return
+	}
+	value, err := time.ParseDuration(strings.TrimSpace(str))
+	if err != nil {
+		value = 0
+		return
+	}
+	ok = true
+	return
+}
+
+//
+// Return the key-value pairs of the Mapping as a go map, keeping the last value of any
+// duplicated key.  A Mapping that cannot be read gives an empty map.
+//
+func (mapping Mapping) toGoMap() (gomap map[string]string) {
+	gomap = make(map[string]string)
+	if len(mapping) < 2 {
+		return
+	}
+	values, _ := mapping.Values()
+	for _, pair := range values {
+		key, _ := pair[0].Data()
+		value, _ := pair[1].Data()
+		gomap[key] = value
+	}
+	return
+}
+
 //
 // Return true if two keys in a mapping are identical.
 //
@@ -136,6 +255,18 @@ func (mapping Mapping) HasDuplicateKeys() bool {
 	return false
 }
 
+//
+// Return the canonical encoding of the Mapping, with the key-value pairs sorted as
+// in ValuesToMapping.  Empty values are preserved as zero length Strings.
+//
+func (mapping Mapping) Bytes() []byte {
+	if len(mapping) < 2 {
+		return ValuesToMapping(MappingValues{})
+	}
+	values, _ := mapping.Values()
+	return ValuesToMapping(values)
+}
+
 //
 // Convert a MappingValue struct to a Mapping.  The values are first
 // sorted in the order defined in mappingOrder.
@@ -143,11 +274,12 @@ func (mapping Mapping) HasDuplicateKeys() bool {
 func ValuesToMapping(values MappingValues) (mapping Mapping) {
 	mappingOrder(values)
 	for _, kv_pair := range values {
-		key_string := kv_pair[0]
-		key_string = append(key_string, []byte("=")[0])
-		key_value := kv_pair[1]
-		key_value = append(key_value, []byte(";")[0])
-		mapping = append(append(mapping, key_string...), key_value...)
+		// Append to the output rather than to the Strings themselves, which may
+		// share a backing array with the Mapping they were parsed from.
+		mapping = append(mapping, kv_pair[0]...)
+		mapping = append(mapping, 0x3d)
+		mapping = append(mapping, kv_pair[1]...)
+		mapping = append(mapping, 0x3b)
 	}
 	map_len := len(mapping)
 	len_bytes := make([]byte, 2)
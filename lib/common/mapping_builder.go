@@ -0,0 +1,100 @@
+package common
+
+import (
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+// Largest number of bytes a Mapping can hold after its 2 byte size.
+const MAPPING_MAX_SIZE = 65535
+
+//
+// A MappingBuilder collects typed options and produces a canonically ordered Mapping.
+// A key that is put twice keeps the last value.  The first invalid key or value is
+// remembered and reported by Build.
+//
+type MappingBuilder struct {
+	values map[string]string
+	err    error
+}
+
+//
+// Create an empty MappingBuilder.
+//
+func NewMappingBuilder() *MappingBuilder {
+	return &MappingBuilder{
+		values: make(map[string]string),
+	}
+}
+
+//
+// Set the option key to the string value.
+//
+func (builder *MappingBuilder) PutString(key, value string) *MappingBuilder {
+	if builder.err != nil {
+		return builder
+	}
+	if err := validMappingEntry(key, value); err != nil {
+		log.WithFields(log.Fields{
+			"at":     "(MappingBuilder) PutString",
+			"key":    key,
+			"reason": err.Error(),
+		}).Error("invalid mapping entry")
+		builder.err = err
+		return builder
+	}
+	builder.values[key] = value
+	return builder
+}
+
+//
+// Set the option key to the decimal representation of value.
+//
+func (builder *MappingBuilder) PutInt(key string, value int) *MappingBuilder {
+	return builder.PutString(key, strconv.Itoa(value))
+}
+
+//
+// Produce the Mapping, or the first error encountered while adding options.
+//
+func (builder *MappingBuilder) Build() (mapping Mapping, err error) {
+	if builder.err != nil {
+		err = builder.err
+		return
+	}
+	mapping, err = GoMapToMapping(builder.values)
+	if err != nil {
+		return
+	}
+	if len(mapping)-2 > MAPPING_MAX_SIZE {
+		log.WithFields(log.Fields{
+			"at":       "(MappingBuilder) Build",
+			"data_len": len(mapping) - 2,
+			"max_len":  MAPPING_MAX_SIZE,
+			"reason":   "too much data",
+		}).Error("cannot create mapping")
+		mapping = nil
+		err = errors.New("error building mapping: too much data")
+	}
+	return
+}
+
+//
+// Check that a key and value can be stored in a Mapping.  Both must fit in an I2P String,
+// and the key must not be empty or contain the '=' and ';' separators.  Values may, as
+// the base64 padding of published keys does.
+//
+func validMappingEntry(key, value string) error {
+	if key == "" {
+		return errors.New("error building mapping: empty key")
+	}
+	if len(key) > STRING_MAX_SIZE || len(value) > STRING_MAX_SIZE {
+		return errors.New("error building mapping: key or value too long")
+	}
+	if strings.ContainsAny(key, "=;") {
+		return errors.New("error building mapping: key contains '=' or ';'")
+	}
+	return nil
+}
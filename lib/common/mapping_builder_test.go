@@ -0,0 +1,66 @@
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMappingBuilderBuildsReadableMapping(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := NewMappingBuilder().
+		PutString("router.version", "0.9.24").
+		PutInt("netId", 2).
+		PutString("caps", "").
+		Build()
+	assert.Nil(err)
+
+	version, present := mapping.Get("router.version")
+	assert.True(present)
+	assert.Equal("0.9.24", version)
+	net_id, present := mapping.Get("netId")
+	assert.True(present)
+	assert.Equal("2", net_id)
+	caps, present := mapping.Get("caps")
+	assert.True(present)
+	assert.Equal("", caps)
+	assert.Equal([]byte(mapping), mapping.Bytes(), "Build() did not produce a canonically ordered mapping")
+}
+
+func TestMappingBuilderKeepsLastValue(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := NewMappingBuilder().PutInt("netId", 2).PutInt("netId", 3).Build()
+	assert.Nil(err)
+	net_id, _ := mapping.Get("netId")
+	assert.Equal("3", net_id)
+	assert.False(mapping.HasDuplicateKeys())
+}
+
+func TestMappingBuilderRejectsInvalidEntries(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewMappingBuilder().PutString("", "value").Build()
+	if assert.NotNil(err) {
+		assert.Equal("error building mapping: empty key", err.Error())
+	}
+	_, err = NewMappingBuilder().PutString("a=b", "value").PutString("c", "d").Build()
+	if assert.NotNil(err) {
+		assert.Equal("error building mapping: key contains '=' or ';'", err.Error())
+	}
+	_, err = NewMappingBuilder().PutString("key", strings.Repeat("a", 256)).Build()
+	if assert.NotNil(err) {
+		assert.Equal("error building mapping: key or value too long", err.Error())
+	}
+}
+
+func TestMappingBuilderAcceptsBase64Padding(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := NewMappingBuilder().PutString("s", "AAAA=").Build()
+	assert.Nil(err)
+	value, present := mapping.Get("s")
+	assert.True(present)
+	assert.Equal("AAAA=", value)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestValuesExclusesPairWithBadData(t *testing.T) {
@@ -185,3 +186,100 @@ func TestBeginsWithCorrectWhenNil(t *testing.T) {
 
 	assert.Equal(beginsWith(slice, 0x41), false, "beginsWith() did not return false on empty slice")
 }
+
+func TestMappingPreservesEmptyValues(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := GoMapToMapping(map[string]string{"a": "", "b": "c"})
+	assert.Nil(err)
+	assert.Equal([]byte{0x00, 0x0b, 0x01, 0x61, 0x3d, 0x00, 0x3b, 0x01, 0x62, 0x3d, 0x01, 0x63, 0x3b}, []byte(mapping))
+
+	values, errs := mapping.Values()
+	assert.Equal(0, len(errs), "Values() reported errors for a mapping with an empty value")
+	if assert.Equal(2, len(values)) {
+		key, _ := values[0][0].Data()
+		val, _ := values[0][1].Data()
+		assert.Equal("a", key)
+		assert.Equal("", val)
+	}
+	assert.Equal([]byte(mapping), mapping.Bytes(), "Bytes() did not round trip a mapping with an empty value")
+	assert.Equal([]byte(mapping), []byte(ValuesToMapping(values)))
+}
+
+func TestMappingBytesIsCanonical(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping := Mapping([]byte{0x00, 0x0b, 0x01, 0x62, 0x3d, 0x01, 0x63, 0x3b, 0x01, 0x61, 0x3d, 0x00, 0x3b})
+	assert.Equal(
+		[]byte{0x00, 0x0b, 0x01, 0x61, 0x3d, 0x00, 0x3b, 0x01, 0x62, 0x3d, 0x01, 0x63, 0x3b},
+		mapping.Bytes(),
+		"Bytes() did not sort the mapping by key",
+	)
+}
+
+func TestMappingBytesWithNoValues(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]byte{0x00, 0x00}, Mapping([]byte{0x00, 0x00}).Bytes())
+}
+
+func TestParseMappingLimitedStopsAtLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := GoMapToMapping(map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"})
+	assert.Nil(err)
+
+	values, errs := ParseMappingLimited(mapping, 2)
+	assert.Equal(2, len(values))
+	if assert.Equal(1, len(errs)) {
+		assert.Equal("error parsing mapping: too many pairs", errs[0].Error())
+	}
+
+	values, errs = ParseMappingLimited(mapping, 4)
+	assert.Equal(4, len(values))
+	assert.Equal(0, len(errs))
+}
+
+func TestParseMappingLimitedWithNoData(t *testing.T) {
+	assert := assert.New(t)
+
+	_, errs := ParseMappingLimited([]byte{0x00}, 4)
+	if assert.Equal(1, len(errs)) {
+		assert.Equal("error parsing mapping: not enough data", errs[0].Error())
+	}
+}
+
+func TestGetBoolParsesTextualForms(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := GoMapToMapping(map[string]string{"a": "true", "b": "1", "c": "No", "d": "maybe"})
+	assert.Nil(err)
+	value, ok := mapping.GetBool("a")
+	assert.True(ok)
+	assert.True(value)
+	value, ok = mapping.GetBool("b")
+	assert.True(ok)
+	assert.True(value)
+	value, ok = mapping.GetBool("c")
+	assert.True(ok)
+	assert.False(value)
+	_, ok = mapping.GetBool("d")
+	assert.False(ok)
+	_, ok = mapping.GetBool("missing")
+	assert.False(ok)
+}
+
+func TestGetDurationParsesDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	mapping, err := GoMapToMapping(map[string]string{"timeout": "30s", "bad": "30"})
+	assert.Nil(err)
+	value, ok := mapping.GetDuration("timeout")
+	assert.True(ok)
+	assert.Equal(30*time.Second, value)
+	value, ok = mapping.GetDuration("bad")
+	assert.False(ok)
+	assert.Equal(time.Duration(0), value)
+	_, ok = mapping.GetDuration("missing")
+	assert.False(ok)
+}
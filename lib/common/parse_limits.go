@@ -0,0 +1,179 @@
+package common
+
+import (
+	"errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Spec maxima for the counts and lengths bounded by ParseLimits
+const (
+	PARSE_LIMIT_MAX_ADDRESSES    = 255
+	PARSE_LIMIT_MAX_MAPPING_SIZE = 65535
+	PARSE_LIMIT_MAX_LEASES       = LEASE_SET_MAX_LEASES
+)
+
+//
+// Most key-value pairs that fit in a Mapping of PARSE_LIMIT_MAX_MAPPING_SIZE bytes, as
+// each pair takes at least four bytes: two String lengths, '=' and ';'.
+//
+const PARSE_LIMIT_MAX_MAPPING_PAIRS = PARSE_LIMIT_MAX_MAPPING_SIZE / 4
+
+//
+// Default bound on the total size of a RouterInfo.  The specification has no total
+// size limit, so this is set far above any RouterInfo seen on the network while still
+// bounding the memory spent decompressing one.
+//
+const PARSE_LIMIT_MAX_ROUTER_INFO_SIZE = 1 << 20
+
+//
+// Bounds on the structures read by ReadRouterInfo and ReadLeaseSet, so a caller parsing
+// untrusted data can limit the resources spent on it.  Counts and lengths read from the
+// data are checked against these limits before the structures they describe are read.
+// A zero field allows the default, so the zero ParseLimits matches DefaultParseLimits().
+//
+type ParseLimits struct {
+	// Most RouterAddresses a RouterInfo may contain
+	MaxAddresses int
+	// Most bytes of key-value data in any Mapping, not counting its two byte size
+	MaxMappingSize int
+	// Most key-value pairs in any Mapping
+	MaxMappingPairs int
+	// Most Leases a LeaseSet may contain
+	MaxLeases int
+	// Most bytes in a RouterInfo, after decompression if it is stored compressed
+	MaxRouterInfoSize int
+}
+
+//
+// Return the ParseLimits allowing every count and length the specification allows, and
+// RouterInfos of up to PARSE_LIMIT_MAX_ROUTER_INFO_SIZE bytes.
+//
+func DefaultParseLimits() ParseLimits {
+	return ParseLimits{
+		MaxAddresses:      PARSE_LIMIT_MAX_ADDRESSES,
+		MaxMappingSize:    PARSE_LIMIT_MAX_MAPPING_SIZE,
+		MaxMappingPairs:   PARSE_LIMIT_MAX_MAPPING_PAIRS,
+		MaxLeases:         PARSE_LIMIT_MAX_LEASES,
+		MaxRouterInfoSize: PARSE_LIMIT_MAX_ROUTER_INFO_SIZE,
+	}
+}
+
+//
+// Return the address limit, or the spec maximum if MaxAddresses is zero.
+//
+func (limits ParseLimits) maxAddresses() int {
+	if limits.MaxAddresses == 0 {
+		return PARSE_LIMIT_MAX_ADDRESSES
+	}
+	return limits.MaxAddresses
+}
+
+//
+// Return the Mapping size limit, or the spec maximum if MaxMappingSize is zero.
+//
+func (limits ParseLimits) maxMappingSize() int {
+	if limits.MaxMappingSize == 0 {
+		return PARSE_LIMIT_MAX_MAPPING_SIZE
+	}
+	return limits.MaxMappingSize
+}
+
+//
+// Return the Mapping pair limit, or the spec maximum if MaxMappingPairs is zero.
+//
+func (limits ParseLimits) maxMappingPairs() int {
+	if limits.MaxMappingPairs == 0 {
+		return PARSE_LIMIT_MAX_MAPPING_PAIRS
+	}
+	return limits.MaxMappingPairs
+}
+
+//
+// Return the lease limit, or the spec maximum if MaxLeases is zero.
+//
+func (limits ParseLimits) maxLeases() int {
+	if limits.MaxLeases == 0 {
+		return PARSE_LIMIT_MAX_LEASES
+	}
+	return limits.MaxLeases
+}
+
+//
+// Return the RouterInfo size limit, or the default if MaxRouterInfoSize is zero.
+//
+func (limits ParseLimits) maxRouterInfoSize() int {
+	if limits.MaxRouterInfoSize == 0 {
+		return PARSE_LIMIT_MAX_ROUTER_INFO_SIZE
+	}
+	return limits.MaxRouterInfoSize
+}
+
+//
+// Return an error naming what is being parsed if value is over limit.
+//
+func (limits ParseLimits) check(value, limit int, at, structure, reason string) (err error) {
+	if value > limit {
+		log.WithFields(log.Fields{
+			"at":     at,
+			"value":  value,
+			"limit":  limit,
+			"reason": reason,
+		}).Error("error parsing " + structure)
+		err = errors.New("error parsing " + structure + ": " + reason)
+	}
+	return
+}
+
+//
+// Check the size of the options Mapping of the RouterAddress at the start of data
+// without reading the RouterAddress.  Data too short to hold the size is left for
+// ReadRouterAddress to report.
+//
+func (limits ParseLimits) checkRouterAddressOptions(data []byte) (err error) {
+	if len(data) < ROUTER_ADDRESS_MIN_SIZE+1 {
+		return
+	}
+	style_len, _, err := NewInteger(data[ROUTER_ADDRESS_MIN_SIZE:], 1)
+	if err != nil {
+		return
+	}
+	head := ROUTER_ADDRESS_MIN_SIZE + 1 + style_len
+	if len(data) < head+2 {
+		return
+	}
+	options_size, _, err := NewInteger(data[head:], 2)
+	if err != nil {
+		return
+	}
+	return limits.check(
+		options_size,
+		limits.maxMappingSize(),
+		"ReadRouterInfo",
+		"router address",
+		"options mapping too large",
+	)
+}
+
+//
+// Parse mapping with ParseMappingLimited, returning an error naming what is being parsed
+// if it holds more key-value pairs than allowed.  Other problems with the Mapping are left
+// for its accessors to report.
+//
+func (limits ParseLimits) checkMappingPairs(mapping Mapping, at, structure string) (err error) {
+	if len(mapping) < 2 {
+		return
+	}
+	_, errs := ParseMappingLimited(mapping, limits.maxMappingPairs())
+	for _, mapping_err := range errs {
+		if mapping_err == ErrTooManyMappingPairs {
+			log.WithFields(log.Fields{
+				"at":     at,
+				"limit":  limits.maxMappingPairs(),
+				"reason": "options mapping has too many pairs",
+			}).Error("error parsing " + structure)
+			err = errors.New("error parsing " + structure + ": options mapping has too many pairs")
+			return
+		}
+	}
+	return
+}
@@ -36,8 +36,13 @@ options :: Mapping
 */
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
 	log "github.com/sirupsen/logrus"
+	"strconv"
+	"time"
 )
 
 // Minimum number of bytes in a valid RouterAddress
@@ -45,8 +50,118 @@ const (
 	ROUTER_ADDRESS_MIN_SIZE = 9
 )
 
+// Number of introducer option groups a RouterAddress may publish, numbered 0 through 2
+const (
+	ROUTER_ADDRESS_MAX_INTRODUCERS = 3
+)
+
+// Option keys common to RouterAddresses of every transport style
+const (
+	ROUTER_ADDRESS_OPTION_HOST = "host"
+	ROUTER_ADDRESS_OPTION_PORT = "port"
+)
+
+// Option keys shared by the NTCP2 and SSU2 transport styles
+const (
+	ROUTER_ADDRESS_OPTION_STATIC_KEY = "s"
+	ROUTER_ADDRESS_OPTION_VERSION    = "v"
+)
+
+// Formats of the numbered option keys of the introducers of a RouterAddress
+const (
+	ROUTER_ADDRESS_OPTION_INTRODUCER_HOST       = "ihost%d"
+	ROUTER_ADDRESS_OPTION_INTRODUCER_PORT       = "iport%d"
+	ROUTER_ADDRESS_OPTION_INTRODUCER_KEY        = "ikey%d"
+	ROUTER_ADDRESS_OPTION_INTRODUCER_HASH       = "ih%d"
+	ROUTER_ADDRESS_OPTION_INTRODUCER_TAG        = "itag%d"
+	ROUTER_ADDRESS_OPTION_INTRODUCER_EXPIRATION = "iexp%d"
+)
+
+// Transport style and option keys of NTCP2 RouterAddresses
+const (
+	NTCP2_TRANSPORT_STYLE = "NTCP2"
+	NTCP2_OPTION_IV       = "i"
+)
+
+//
+// Options every RouterAddress of a transport style must publish.  Addresses of styles
+// not listed here have no required options.
+//
+var transport_required_options = map[string][]string{
+	NTCP2_TRANSPORT_STYLE: {ROUTER_ADDRESS_OPTION_STATIC_KEY, ROUTER_ADDRESS_OPTION_VERSION},
+	SSU2_TRANSPORT_STYLE:  {ROUTER_ADDRESS_OPTION_STATIC_KEY, SSU2_OPTION_INTRO_KEY, ROUTER_ADDRESS_OPTION_VERSION},
+}
+
+//
+// Options a RouterAddress of a transport style must publish when it publishes a host,
+// in addition to those in transport_required_options.
+//
+var transport_published_options = map[string][]string{
+	NTCP2_TRANSPORT_STYLE: {ROUTER_ADDRESS_OPTION_PORT, NTCP2_OPTION_IV},
+	SSU2_TRANSPORT_STYLE:  {ROUTER_ADDRESS_OPTION_PORT},
+}
+
 type RouterAddress []byte
 
+//
+// An introducer published by a firewalled router in the numbered introducer options of
+// its RouterAddress.  SSU introducers are given by Host, Port and Key from the ihostN,
+// iportN and ikeyN options, SSU2 introducers by the router Hash in the ihN option, and
+// both carry the Tag and optional Expiration of the itagN and iexpN options.
+//
+type Introducer struct {
+	Host       string
+	Port       int
+	Key        [32]byte
+	Hash       Hash
+	Tag        uint32
+	Expiration time.Time
+}
+
+//
+// Assemble a RouterAddress from its components, returning an error if cost does not fit
+// in the one byte cost field or the transport style or options cannot be encoded.  The
+// options are built with a MappingBuilder, so they are checked and canonically ordered.
+//
+func NewRouterAddressFromComponents(
+	cost int,
+	expiration Date,
+	transport_style string,
+	options map[string]string,
+) (router_address RouterAddress, err error) {
+	if cost < 0 || cost > 255 {
+		log.WithFields(log.Fields{
+			"at":     "NewRouterAddressFromComponents",
+			"cost":   cost,
+			"reason": "cost must be 0-255",
+		}).Error("error creating router address")
+		err = errors.New("error creating router address: cost out of range")
+		return
+	}
+	if transport_style == "" {
+		err = errors.New("error creating router address: zero length transport style")
+		return
+	}
+	style, err := ToI2PString(transport_style)
+	if err != nil {
+		return
+	}
+	builder := NewMappingBuilder()
+	for key, value := range options {
+		builder.PutString(key, value)
+	}
+	mapping, err := builder.Build()
+	if err != nil {
+		return
+	}
+	router_address = make(RouterAddress, 0, ROUTER_ADDRESS_MIN_SIZE+len(style)+len(mapping))
+	router_address = append(router_address, byte(cost))
+	router_address = append(router_address, expiration[:]...)
+	router_address = append(router_address, style...)
+	router_address = append(router_address, mapping...)
+	return
+}
+
 //
 // Return the cost integer for this RouterAddress and any errors encountered
 // parsing the RouterAddress.
@@ -56,7 +171,10 @@ func (router_address RouterAddress) Cost() (cost int, err error) {
 	if exit {
 		return
 	}
-	cost = Integer([]byte{router_address[0]})
+	cost, _, cost_err := NewInteger(router_address, 1)
+	if cost_err != nil {
+		err = cost_err
+	}
 	return
 }
 
@@ -103,6 +221,243 @@ func (router_address RouterAddress) Options() (mapping Mapping, err error) {
 	return
 }
 
+//
+// Return the value of the host option of this RouterAddress, or an error if it is
+// missing.
+//
+func (router_address RouterAddress) Host() (host string, err error) {
+	options, err := router_address.Options()
+	if err != nil {
+		return
+	}
+	host, present := options.Get(ROUTER_ADDRESS_OPTION_HOST)
+	if !present || host == "" {
+		err = errors.New("error parsing RouterAddress: missing host option")
+	}
+	return
+}
+
+//
+// Return the value of the port option of this RouterAddress, or an error if it is
+// missing or not a valid port number.
+//
+func (router_address RouterAddress) Port() (port int, err error) {
+	options, err := router_address.Options()
+	if err != nil {
+		return
+	}
+	port_str, present := options.Get(ROUTER_ADDRESS_OPTION_PORT)
+	if !present {
+		err = errors.New("error parsing RouterAddress: missing port option")
+		return
+	}
+	port, perr := strconv.Atoi(port_str)
+	if perr != nil || port < 1 || port > 65535 {
+		port = 0
+		err = errors.New("error parsing RouterAddress: invalid port option")
+	}
+	return
+}
+
+//
+// Check that this RouterAddress publishes the options its transport style requires.  An
+// address that publishes a host must also publish whatever a peer needs to dial it.
+// Addresses of firewalled or outbound-only routers omit the host, and per the NTCP2 and
+// SSU2 specifications then omit the port and NTCP2 i option too, so only the options
+// needed to handshake with the router are required of them.
+//
+func (router_address RouterAddress) ValidateForTransport() (err error) {
+	style, err := router_address.TransportStyle()
+	if err != nil {
+		return
+	}
+	style_str, _ := style.Data()
+	options, err := router_address.Options()
+	if err != nil {
+		return
+	}
+	required := transport_required_options[style_str]
+	if _, published := options.Get(ROUTER_ADDRESS_OPTION_HOST); published {
+		required = append(append([]string{}, required...), transport_published_options[style_str]...)
+	}
+	for _, key := range required {
+		if _, present := options.Get(key); !present {
+			log.WithFields(log.Fields{
+				"at":              "(RouterAddress) ValidateForTransport",
+				"transport_style": style_str,
+				"option":          key,
+				"reason":          "required option missing",
+			}).Error("invalid router address")
+			err = fmt.Errorf("error parsing RouterAddress: %s address missing %s option", style_str, key)
+			return
+		}
+	}
+	return
+}
+
+//
+// Return the introducers published in the numbered introducer options of this RouterAddress.
+// A group is present when its ihostN or ihN option is.  An ihostN group must also carry
+// iportN and ikeyN, every group must carry itagN, and iexpN is optional.
+//
+func (router_address RouterAddress) Introducers() (introducers []Introducer, err error) {
+	options, err := router_address.Options()
+	if err != nil {
+		return
+	}
+	for i := 0; i < ROUTER_ADDRESS_MAX_INTRODUCERS; i++ {
+		host, has_host := options.Get(fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_HOST, i))
+		hash_key := fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_HASH, i)
+		hash_str, has_hash := options.Get(hash_key)
+		if !has_host && !has_hash {
+			continue
+		}
+		introducer := Introducer{Host: host}
+		if has_host {
+			port_key := fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_PORT, i)
+			port_str, _ := options.Get(port_key)
+			port, perr := strconv.Atoi(port_str)
+			if perr != nil || port < 1 || port > 65535 {
+				err = fmt.Errorf("error parsing introducers: invalid %s option", port_key)
+				return
+			}
+			introducer.Port = port
+			key_key := fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_KEY, i)
+			key_str, _ := options.Get(key_key)
+			key, derr := base64.DecodeFromString(key_str)
+			if derr != nil || len(key) != len(introducer.Key) {
+				err = fmt.Errorf("error parsing introducers: invalid %s option", key_key)
+				return
+			}
+			copy(introducer.Key[:], key)
+		}
+		if has_hash {
+			hash, derr := base64.DecodeFromString(hash_str)
+			if derr != nil || len(hash) != len(introducer.Hash) {
+				err = fmt.Errorf("error parsing introducers: invalid %s option", hash_key)
+				return
+			}
+			copy(introducer.Hash[:], hash)
+		}
+		tag_key := fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_TAG, i)
+		tag_str, present := options.Get(tag_key)
+		if !present {
+			err = fmt.Errorf("error parsing introducers: missing %s option", tag_key)
+			return
+		}
+		tag, perr := strconv.ParseUint(tag_str, 10, 32)
+		if perr != nil {
+			err = fmt.Errorf("error parsing introducers: invalid %s option", tag_key)
+			return
+		}
+		introducer.Tag = uint32(tag)
+		exp_key := fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_EXPIRATION, i)
+		if exp_str, present := options.Get(exp_key); present {
+			exp, perr := strconv.ParseInt(exp_str, 10, 64)
+			if perr != nil {
+				err = fmt.Errorf("error parsing introducers: invalid %s option", exp_key)
+				return
+			}
+			introducer.Expiration = time.Unix(exp, 0)
+		}
+		introducers = append(introducers, introducer)
+	}
+	return
+}
+
+//
+// Set the option key to value, replacing any existing value for key.  The options are
+// written back in canonical order into a new buffer, leaving any RouterInfo the address
+// was read from unchanged.
+//
+func (router_address *RouterAddress) SetOption(key, value String) (err error) {
+	values, err := router_address.optionValues()
+	if err != nil {
+		return
+	}
+	replaced := false
+	for i, kv_pair := range values {
+		if bytes.Equal(kv_pair[0], key) {
+			values[i][1] = value
+			replaced = true
+		}
+	}
+	if !replaced {
+		values = append(values, [2]String{key, value})
+	}
+	err = router_address.replaceOptions(values)
+	return
+}
+
+//
+// Remove the option key if it is present.
+//
+func (router_address *RouterAddress) RemoveOption(key String) (err error) {
+	values, err := router_address.optionValues()
+	if err != nil {
+		return
+	}
+	kept := MappingValues{}
+	for _, kv_pair := range values {
+		if !bytes.Equal(kv_pair[0], key) {
+			kept = append(kept, kv_pair)
+		}
+	}
+	err = router_address.replaceOptions(kept)
+	return
+}
+
+//
+// Return the parsed options of this RouterAddress, or no values if it has no Mapping.
+//
+func (router_address RouterAddress) optionValues() (values MappingValues, err error) {
+	options, err := router_address.Options()
+	if err != nil {
+		return
+	}
+	values = MappingValues{}
+	if len(options) >= 2 {
+		values, _ = options.Values()
+	}
+	return
+}
+
+//
+// Replace the Mapping of this RouterAddress with one built from values.
+//
+func (router_address *RouterAddress) replaceOptions(values MappingValues) (err error) {
+	style, err := router_address.TransportStyle()
+	if err != nil {
+		return
+	}
+	mapping := ValuesToMapping(values)
+	head := ROUTER_ADDRESS_MIN_SIZE + len(style)
+	updated := make(RouterAddress, 0, head+len(mapping))
+	updated = append(updated, (*router_address)[:head]...)
+	updated = append(updated, mapping...)
+	*router_address = updated
+	return
+}
+
+//
+// Return a human readable summary of the RouterAddress's cost, transport style
+// and number of options.
+//
+func (router_address RouterAddress) String() string {
+	cost, err := router_address.Cost()
+	if err != nil {
+		return fmt.Sprintf("RouterAddress{invalid: %d bytes}", len(router_address))
+	}
+	style, _ := router_address.TransportStyle()
+	style_str, _ := style.Data()
+	option_count := 0
+	if options, err := router_address.Options(); err == nil && len(options) >= 2 {
+		values, _ := options.Values()
+		option_count = len(values)
+	}
+	return fmt.Sprintf("RouterAddress{cost: %d, style: %s, options: %d}", cost, style_str, option_count)
+}
+
 //
 // Check if the RouterAddress is empty or if it is too small to contain valid data.
 //
@@ -141,13 +496,25 @@ func ReadRouterAddress(data []byte) (router_address RouterAddress, remainder []b
 	if err != nil {
 		return
 	}
+	if len(str) < 2 {
+		log.WithFields(log.Fields{
+			"at":     "ReadRouterAddress",
+			"reason": "transport style must be 1-256 bytes",
+		}).Error("invalid router address")
+		err = errors.New("error parsing RouterAddress: zero length transport style")
+		DumpStructure("RouterAddress", data, ROUTER_ADDRESS_MIN_SIZE)
+		router_address = RouterAddress([]byte{})
+		remainder = []byte{}
+		return
+	}
 	router_address = append(router_address, str...)
 	map_size := 0
 	mapping := make([]byte, 0)
 	if len(remainder) >= 2 {
-		map_size = Integer(remainder[:2])
-		if len(remainder) < map_size+2 {
+		map_size, _, err = NewInteger(remainder, 2)
+		if err != nil || len(remainder) < map_size+2 {
 			err = errors.New("not enough data for map inside router address")
+			DumpStructure("RouterAddress", data, ROUTER_ADDRESS_MIN_SIZE+len(str))
 			router_address = RouterAddress([]byte{})
 			remainder = []byte{}
 			return
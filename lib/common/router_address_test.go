@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestCheckValidReportsEmptySlice(t *testing.T) {
@@ -33,9 +34,7 @@ func TestCheckRouterAddressValidReportsDataMissing(t *testing.T) {
 func TestCheckRouterAddressValidNoErrWithValidData(t *testing.T) {
 	assert := assert.New(t)
 
-	router_address := RouterAddress([]byte{0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00})
-	mapping, _ := GoMapToMapping(map[string]string{"host": "127.0.0.1", "port": "4567"})
-	router_address = append(router_address, mapping...)
+	router_address := buildRouterAddress("NTCP2")
 	err, exit := router_address.checkValid()
 
 	assert.Nil(err, "checkValid() reported error with valid data")
@@ -45,7 +44,7 @@ func TestCheckRouterAddressValidNoErrWithValidData(t *testing.T) {
 func TestRouterAddressCostReturnsFirstByte(t *testing.T) {
 	assert := assert.New(t)
 
-	router_address := RouterAddress([]byte{0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00})
+	router_address := buildRouterAddress("NTCP2")
 	cost, err := router_address.Cost()
 
 	assert.Nil(err, "Cost() returned error with valid data")
@@ -67,11 +66,7 @@ func TestRouterAddressExpirationReturnsCorrectData(t *testing.T) {
 func TestReadRouterAddressReturnsCorrectRemainderWithoutError(t *testing.T) {
 	assert := assert.New(t)
 
-	router_address_bytes := []byte{0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-	str, _ := ToI2PString("foo")
-	mapping, _ := GoMapToMapping(map[string]string{"host": "127.0.0.1", "port": "4567"})
-	router_address_bytes = append(router_address_bytes, []byte(str)...)
-	router_address_bytes = append(router_address_bytes, mapping...)
+	router_address_bytes := []byte(buildRouterAddress("foo"))
 	router_address_bytes = append(router_address_bytes, []byte{0x01, 0x02, 0x03}...)
 	router_address, remainder, err := ReadRouterAddress(router_address_bytes)
 
@@ -95,3 +90,291 @@ func TestCorrectsFuzzCrasher1(t *testing.T) {
 	router_address_bytes := []byte{0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x00, 0x30, 0x30}
 	ReadRouterAddress(router_address_bytes)
 }
+
+func TestRouterAddressStringShowsSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	str := buildRouterAddress("NTCP2").String()
+
+	assert.Contains(str, "cost: 6")
+	assert.Contains(str, "style: NTCP2")
+	assert.Contains(str, "options: 2")
+}
+
+func TestRouterAddressSetOptionChangesPort(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddress("NTCP2")
+	original := append([]byte{}, router_address...)
+	port_key, _ := ToI2PString("port")
+	port_value, _ := ToI2PString("4568")
+	err := router_address.SetOption(port_key, port_value)
+	assert.Nil(err)
+
+	options, err := router_address.Options()
+	assert.Nil(err)
+	port, present := options.Get("port")
+	assert.True(present)
+	assert.Equal("4568", port)
+	host, present := options.Get("host")
+	assert.True(present)
+	assert.Equal("127.0.0.1", host)
+	assert.False(options.HasDuplicateKeys())
+	style, _ := router_address.TransportStyle()
+	style_str, _ := style.Data()
+	assert.Equal("NTCP2", style_str)
+
+	read, remainder, err := ReadRouterAddress(router_address)
+	assert.Nil(err)
+	assert.Equal(0, len(remainder))
+	assert.Equal(router_address, read)
+	assert.NotEqual(original, []byte(router_address))
+}
+
+func TestRouterAddressRemoveOption(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddress("NTCP2")
+	host_key, _ := ToI2PString("host")
+	err := router_address.RemoveOption(host_key)
+	assert.Nil(err)
+
+	options, err := router_address.Options()
+	assert.Nil(err)
+	_, present := options.Get("host")
+	assert.False(present)
+	_, present = options.Get("port")
+	assert.True(present)
+}
+
+func TestReadRouterAddressRejectsZeroLengthTransportStyle(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address, _, err := ReadRouterAddress(buildRouterAddress(""))
+
+	if assert.NotNil(err) {
+		assert.Equal("error parsing RouterAddress: zero length transport style", err.Error())
+	}
+	assert.Equal(0, len(router_address))
+}
+
+func TestRouterAddressIntroducers(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddressWithOptions("SSU", map[string]string{
+		"caps":   "BC",
+		"ihost0": "10.0.0.1",
+		"iport0": "12345",
+		"ikey0":  buildKeyString(0x01),
+		"itag0":  "1001",
+		"iexp0":  "1600000000",
+		"ihost1": "10.0.0.2",
+		"iport1": "23456",
+		"ikey1":  buildKeyString(0x02),
+		"itag1":  "1002",
+	})
+
+	introducers, err := router_address.Introducers()
+	assert.Nil(err)
+	if assert.Equal(2, len(introducers)) {
+		assert.Equal("10.0.0.1", introducers[0].Host)
+		assert.Equal(12345, introducers[0].Port)
+		assert.Equal(byte(0x01), introducers[0].Key[31])
+		assert.Equal(uint32(1001), introducers[0].Tag)
+		assert.Equal(int64(1600000000), introducers[0].Expiration.Unix())
+		assert.Equal("10.0.0.2", introducers[1].Host)
+		assert.Equal(23456, introducers[1].Port)
+		assert.Equal(byte(0x02), introducers[1].Key[0])
+		assert.Equal(uint32(1002), introducers[1].Tag)
+		assert.True(introducers[1].Expiration.IsZero())
+	}
+}
+
+func TestRouterAddressIntroducersReportsMissingKey(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddressWithOptions("SSU", map[string]string{"ihost0": "10.0.0.1", "iport0": "12345", "itag0": "1"})
+
+	_, err := router_address.Introducers()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing introducers: invalid ikey0 option", err.Error())
+	}
+}
+
+func TestRouterAddressHostAndPortReadOptionConstants(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddressWithOptions("NTCP2", map[string]string{
+		ROUTER_ADDRESS_OPTION_HOST: "10.0.0.1",
+		ROUTER_ADDRESS_OPTION_PORT: "12345",
+	})
+
+	host, err := router_address.Host()
+	assert.Nil(err)
+	assert.Equal("10.0.0.1", host)
+	port, err := router_address.Port()
+	assert.Nil(err)
+	assert.Equal(12345, port)
+}
+
+func TestRouterAddressPortRejectsInvalidPort(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddressWithOptions("NTCP2", map[string]string{ROUTER_ADDRESS_OPTION_PORT: "70000"})
+
+	_, err := router_address.Host()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing RouterAddress: missing host option", err.Error())
+	}
+	_, err = router_address.Port()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing RouterAddress: invalid port option", err.Error())
+	}
+}
+
+// build a RouterAddress of the given transport style with the buildMapping options
+func buildRouterAddress(transport string) RouterAddress {
+	return buildRouterAddressWithOptions(transport, map[string]string{"host": "127.0.0.1", "port": "4567"})
+}
+
+// build a RouterAddress with cost 6 and no expiration of the given transport style with
+// the given options, every RouterAddress fixture in this package is built here
+func buildRouterAddressWithOptions(transport string, options map[string]string) RouterAddress {
+	router_address_bytes := []byte{0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	str, _ := ToI2PString(transport)
+	mapping, _ := GoMapToMapping(options)
+	router_address_bytes = append(router_address_bytes, []byte(str)...)
+	router_address_bytes = append(router_address_bytes, mapping...)
+	return RouterAddress(router_address_bytes)
+}
+
+func TestValidateForTransportAcceptsNTCP2Address(t *testing.T) {
+	assert := assert.New(t)
+
+	key := buildKeyString(0x01)
+	published := buildRouterAddressWithOptions(NTCP2_TRANSPORT_STYLE, map[string]string{
+		"host": "10.0.0.1",
+		"port": "12345",
+		"s":    key,
+		"i":    key,
+		"v":    "2",
+	})
+	assert.Nil(published.ValidateForTransport())
+	unpublished := buildRouterAddressWithOptions(NTCP2_TRANSPORT_STYLE, map[string]string{"s": key, "v": "2"})
+	assert.Nil(unpublished.ValidateForTransport())
+}
+
+func TestValidateForTransportReportsMissingStaticKey(t *testing.T) {
+	assert := assert.New(t)
+
+	key := buildKeyString(0x01)
+	router_address := buildRouterAddressWithOptions(NTCP2_TRANSPORT_STYLE, map[string]string{
+		"host": "10.0.0.1",
+		"port": "12345",
+		"i":    key,
+		"v":    "2",
+	})
+	err := router_address.ValidateForTransport()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing RouterAddress: NTCP2 address missing s option", err.Error())
+	}
+}
+
+func TestValidateForTransportReportsMissingIVOnPublishedAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address := buildRouterAddressWithOptions(NTCP2_TRANSPORT_STYLE, map[string]string{
+		"host": "10.0.0.1",
+		"port": "12345",
+		"s":    buildKeyString(0x01),
+		"v":    "2",
+	})
+	err := router_address.ValidateForTransport()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing RouterAddress: NTCP2 address missing i option", err.Error())
+	}
+}
+
+func TestValidateForTransportAcceptsAddressesWithoutHost(t *testing.T) {
+	assert := assert.New(t)
+
+	key := buildKeyString(0x01)
+	outbound_only := buildRouterAddressWithOptions(NTCP2_TRANSPORT_STYLE, map[string]string{"s": key, "v": "2"})
+	assert.Nil(outbound_only.ValidateForTransport())
+	firewalled := buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"s":     key,
+		"i":     key,
+		"v":     "2",
+		"ih0":   buildKeyString(0x03),
+		"itag0": "1234",
+	})
+	assert.Nil(firewalled.ValidateForTransport())
+}
+
+func TestValidateForTransportReportsMissingStaticKeyWithoutHost(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, style := range []string{NTCP2_TRANSPORT_STYLE, SSU2_TRANSPORT_STYLE} {
+		router_address := buildRouterAddressWithOptions(style, map[string]string{"i": buildKeyString(0x01), "v": "2"})
+		err := router_address.ValidateForTransport()
+		if assert.NotNil(err, style) {
+			assert.Equal("error parsing RouterAddress: "+style+" address missing s option", err.Error())
+		}
+	}
+}
+
+func TestValidateForTransportIgnoresUnknownStyles(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(buildRouterAddressWithOptions("foo", map[string]string{}).ValidateForTransport())
+}
+
+func TestNewRouterAddressFromComponentsRoundTrips(t *testing.T) {
+	assert := assert.New(t)
+
+	expiration := NewDate(time.Unix(1600000000, 0))
+	router_address, err := NewRouterAddressFromComponents(10, expiration, "NTCP2", map[string]string{"host": "10.0.0.1"})
+	assert.Nil(err)
+	read, remainder, err := ReadRouterAddress(router_address)
+	assert.Nil(err)
+	assert.Empty(remainder)
+	assert.Equal(router_address, read)
+	cost, err := read.Cost()
+	assert.Nil(err)
+	assert.Equal(10, cost)
+	read_expiration, err := read.Expiration()
+	assert.Nil(err)
+	assert.Equal(expiration, read_expiration)
+	host, err := read.Host()
+	assert.Nil(err)
+	assert.Equal("10.0.0.1", host)
+}
+
+func TestNewRouterAddressFromComponentsRejectsInvalidOption(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address, err := NewRouterAddressFromComponents(10, Date{}, "NTCP2", map[string]string{"host;port": "10.0.0.1"})
+	assert.Nil(router_address)
+	if assert.NotNil(err) {
+		assert.Equal("error building mapping: key contains '=' or ';'", err.Error())
+	}
+}
+
+func TestNewRouterAddressFromComponentsRejectsCostOverByte(t *testing.T) {
+	assert := assert.New(t)
+
+	router_address, err := NewRouterAddressFromComponents(300, Date{}, "NTCP2", nil)
+	assert.Nil(router_address)
+	if assert.NotNil(err) {
+		assert.Equal("error creating router address: cost out of range", err.Error())
+	}
+}
+
+func TestNewRouterAddressFromComponentsRejectsNegativeCost(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewRouterAddressFromComponents(-1, Date{}, "NTCP2", nil)
+	if assert.NotNil(err) {
+		assert.Equal("error creating router address: cost out of range", err.Error())
+	}
+}
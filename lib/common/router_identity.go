@@ -9,7 +9,10 @@ Identical to KeysAndCert
 */
 
 import (
+	"errors"
 	"github.com/go-i2p/go-i2p/lib/crypto"
+	log "github.com/sirupsen/logrus"
+	"io"
 )
 
 //
@@ -34,3 +37,84 @@ func ReadRouterIdentity(data []byte) (router_identity RouterIdentity, remainder
 	router_identity = RouterIdentity(keys_and_cert)
 	return
 }
+
+//
+// Assemble a RouterIdentity from its keys and Certificate.  The PublicKey is stored at the
+// start of its field and the SigningPublicKey at the end of its field, as read back by
+// KeyCertificate, with zero padding between them.  Keys too large for their fields are
+// rejected.
+//
+func NewRouterIdentity(
+	public_key crypto.PublicKey,
+	signing_public_key crypto.SigningPublicKey,
+	certificate Certificate,
+) (router_identity RouterIdentity, err error) {
+	return NewRouterIdentityWithPadding(public_key, signing_public_key, certificate, nil)
+}
+
+//
+// Assemble a RouterIdentity for an Ed25519 signing key, building the Key Certificate
+// from the types of the keys.  The public_key must be an ElGamal or X25519 key.
+//
+func NewEd25519RouterIdentity(
+	public_key crypto.PublicKey,
+	signing_public_key crypto.Ed25519PublicKey,
+) (router_identity RouterIdentity, err error) {
+	var crypto_type int
+	switch public_key.(type) {
+	case crypto.ElgPublicKey:
+		crypto_type = KEYCERT_CRYPTO_ELG
+	case crypto.X25519PublicKey:
+		crypto_type = KEYCERT_CRYPTO_X25519
+	default:
+		log.WithFields(log.Fields{
+			"at":     "NewEd25519RouterIdentity",
+			"reason": "unsupported public key type",
+		}).Error("error creating router identity")
+		err = errors.New("error creating router identity: unsupported public key type")
+		return
+	}
+	certificate, err := NewCertificateWithType(CERT_KEY, []byte{0x00, KEYCERT_SIGN_ED25519, 0x00, byte(crypto_type)})
+	if err != nil {
+		return
+	}
+	router_identity, err = NewRouterIdentity(public_key, signing_public_key, certificate)
+	return
+}
+
+//
+// Assemble a RouterIdentity like NewRouterIdentity, filling the padding after each key
+// from padding instead of with zeros.  The amount of padding is fixed by the key sizes,
+// so only its contents differ; a nil padding leaves it zeroed.
+//
+func NewRouterIdentityWithPadding(
+	public_key crypto.PublicKey,
+	signing_public_key crypto.SigningPublicKey,
+	certificate Certificate,
+	padding io.Reader,
+) (router_identity RouterIdentity, err error) {
+	if public_key.Len() > KEYS_AND_CERT_PUBKEY_SIZE || signing_public_key.Len() > KEYS_AND_CERT_SPK_SIZE {
+		log.WithFields(log.Fields{
+			"at":              "NewRouterIdentityWithPadding",
+			"public_key_len":  public_key.Len(),
+			"signing_key_len": signing_public_key.Len(),
+			"reason":          "key larger than its field",
+		}).Error("error creating router identity")
+		err = errors.New("error creating router identity: key larger than its field")
+		return
+	}
+	data := make([]byte, KEYS_AND_CERT_DATA_SIZE, KEYS_AND_CERT_DATA_SIZE+len(certificate))
+	spk_start := KEYS_AND_CERT_DATA_SIZE - signing_public_key.Len()
+	if padding != nil {
+		if _, err = io.ReadFull(padding, data[public_key.Len():KEYS_AND_CERT_PUBKEY_SIZE]); err != nil {
+			return
+		}
+		if _, err = io.ReadFull(padding, data[KEYS_AND_CERT_PUBKEY_SIZE:spk_start]); err != nil {
+			return
+		}
+	}
+	copy(data, public_key.Bytes())
+	copy(data[spk_start:], signing_public_key.Bytes())
+	router_identity = RouterIdentity(append(data, certificate...))
+	return
+}
@@ -0,0 +1,150 @@
+package common
+
+import (
+	"bytes"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewRouterIdentityWithX25519Key(t *testing.T) {
+	assert := assert.New(t)
+
+	var private_key crypto.X25519PrivateKey
+	private_key, err := private_key.Generate()
+	assert.Nil(err)
+	public_key, err := private_key.Public()
+	assert.Nil(err)
+	signing_key := crypto.Ed25519PublicKey(buildSignature(KEYCERT_SIGN_ED25519_SIZE))
+	certificate := Certificate([]byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04})
+
+	router_identity, err := NewRouterIdentity(public_key, signing_key, certificate)
+	assert.Nil(err)
+	assert.Equal(KEYS_AND_CERT_MIN_SIZE+4, len(router_identity))
+
+	read, remainder, err := ReadRouterIdentity(router_identity)
+	assert.Nil(err)
+	assert.Equal(0, len(remainder))
+	cert, err := read.Certificate()
+	assert.Nil(err)
+	crypto_type, err := KeyCertificate(cert).PublicKeyType()
+	assert.Nil(err)
+	assert.Equal(KEYCERT_CRYPTO_X25519, crypto_type)
+	parsed, err := read.PublicKey()
+	if assert.Nil(err) {
+		assert.Equal(public_key, parsed)
+	}
+	assert.Equal(
+		signing_key.Bytes(),
+		[]byte(read[KEYS_AND_CERT_DATA_SIZE-KEYCERT_SIGN_ED25519_SIZE:KEYS_AND_CERT_DATA_SIZE]),
+	)
+}
+
+func TestNewRouterIdentityRejectsOversizedKey(t *testing.T) {
+	assert := assert.New(t)
+
+	var public_key crypto.ElgPublicKey
+	signing_key := crypto.Ed25519PublicKey(make([]byte, KEYS_AND_CERT_SPK_SIZE+1))
+	_, err := NewRouterIdentity(public_key, signing_key, NewNullCertificate())
+	if assert.NotNil(err) {
+		assert.Equal("error creating router identity: key larger than its field", err.Error())
+	}
+}
+
+func TestNewRouterIdentityPaddingReparsesIdentically(t *testing.T) {
+	assert := assert.New(t)
+
+	var private_key crypto.X25519PrivateKey
+	private_key, err := private_key.Generate()
+	assert.Nil(err)
+	public_key, err := private_key.Public()
+	assert.Nil(err)
+	signing_key := crypto.Ed25519PublicKey(buildSignature(KEYCERT_SIGN_ED25519_SIZE))
+	certificate := Certificate([]byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04})
+
+	zeroed, err := NewRouterIdentity(public_key, signing_key, certificate)
+	assert.Nil(err)
+	padded, err := NewRouterIdentityWithPadding(
+		public_key,
+		signing_key,
+		certificate,
+		bytes.NewReader(bytes.Repeat([]byte{0xff}, KEYS_AND_CERT_DATA_SIZE)),
+	)
+	assert.Nil(err)
+	assert.Equal(len(zeroed), len(padded))
+	assert.NotEqual(zeroed, padded)
+	assert.Equal(byte(0xff), padded[crypto.X25519PublicKey{}.Len()])
+	assert.Equal(byte(0xff), padded[KEYS_AND_CERT_DATA_SIZE-KEYCERT_SIGN_ED25519_SIZE-1])
+
+	for _, router_identity := range []RouterIdentity{zeroed, padded} {
+		read, remainder, err := ReadRouterIdentity(router_identity)
+		assert.Nil(err)
+		assert.Equal(0, len(remainder))
+		parsed_public_key, err := read.PublicKey()
+		if assert.Nil(err) {
+			assert.Equal(public_key, parsed_public_key)
+		}
+		parsed_signing_key, err := read.SigningPublicKey()
+		if assert.Nil(err) {
+			assert.Equal(signing_key.Bytes(), parsed_signing_key.Bytes())
+		}
+	}
+}
+
+func TestNewRouterIdentityWithShortPadding(t *testing.T) {
+	assert := assert.New(t)
+
+	var public_key crypto.X25519PublicKey
+	signing_key := crypto.Ed25519PublicKey(buildSignature(KEYCERT_SIGN_ED25519_SIZE))
+	_, err := NewRouterIdentityWithPadding(public_key, signing_key, NewNullCertificate(), bytes.NewReader([]byte{0x01}))
+	assert.NotNil(err)
+}
+
+func TestNewEd25519RouterIdentityParsesBackKeyTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	var public_key crypto.X25519PublicKey
+	public_key[0] = 0x01
+	signing_key := crypto.Ed25519PublicKey(buildSignature(KEYCERT_SIGN_ED25519_SIZE))
+
+	router_identity, err := NewEd25519RouterIdentity(public_key, signing_key)
+	assert.Nil(err)
+	read, remainder, err := ReadRouterIdentity(router_identity)
+	assert.Nil(err)
+	assert.Equal(0, len(remainder))
+	cert, err := read.Certificate()
+	assert.Nil(err)
+	signing_type, err := KeyCertificate(cert).SigningPublicKeyType()
+	assert.Nil(err)
+	assert.Equal(KEYCERT_SIGN_ED25519, signing_type)
+	crypto_type, err := KeyCertificate(cert).PublicKeyType()
+	assert.Nil(err)
+	assert.Equal(KEYCERT_CRYPTO_X25519, crypto_type)
+
+	parsed_public_key, err := read.PublicKey()
+	if assert.Nil(err) {
+		assert.Equal(public_key, parsed_public_key)
+	}
+	parsed_signing_key, err := read.SigningPublicKey()
+	if assert.Nil(err) {
+		_, ok := parsed_signing_key.(crypto.Ed25519PublicKey)
+		assert.True(ok, "SigningPublicKey() did not return an Ed25519PublicKey")
+		assert.Equal(signing_key.Bytes(), parsed_signing_key.Bytes())
+	}
+}
+
+// a public key of a type NewEd25519RouterIdentity cannot describe in a Key Certificate
+type unsupportedPublicKey struct{}
+
+func (unsupportedPublicKey) Len() int                                { return 0 }
+func (unsupportedPublicKey) Bytes() []byte                           { return nil }
+func (unsupportedPublicKey) NewEncrypter() (crypto.Encrypter, error) { return nil, nil }
+
+func TestNewEd25519RouterIdentityRejectsUnsupportedKey(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewEd25519RouterIdentity(unsupportedPublicKey{}, crypto.Ed25519PublicKey(buildSignature(KEYCERT_SIGN_ED25519_SIZE)))
+	if assert.NotNil(err) {
+		assert.Equal("error creating router identity: unsupported public key type", err.Error())
+	}
+}
@@ -70,16 +70,306 @@ peer_size :: Integer
 options :: Mapping
 
 signature :: Signature
-             length -> 40 bytes
+             length -> 40 bytes or as specified in router_ident's key certificate
 */
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/go-i2p/go-i2p/lib/common/base32"
+	"github.com/go-i2p/go-i2p/lib/crypto"
 	log "github.com/sirupsen/logrus"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 type RouterInfo []byte
 
+// How a router can be contacted by its peers.
+type Reachability int
+
+// Longest time after publication a RouterInfo is considered current by Validate
+const ROUTER_INFO_MAX_AGE = 72 * time.Hour
+
+// Furthest in the future a RouterInfo may be published and pass Validate, allowing for
+// the publisher's clock running ahead of ours
+const ROUTER_INFO_MAX_CLOCK_SKEW = 2 * time.Minute
+
+// Errors returned by Validate and ValidateRequiredOptions, for matching with errors.Is
+var (
+	ErrRouterInfoTooShort     = errors.New("error validating router info: not enough data")
+	ErrMissingNetID           = errors.New("invalid router info options: missing netId")
+	ErrNonNumericNetID        = errors.New("invalid router info options: netId is not numeric")
+	ErrMissingRouterVersion   = errors.New("invalid router info options: missing router.version")
+	ErrMalformedRouterVersion = errors.New("invalid router info options: malformed router.version")
+	ErrMalformedCoreVersion   = errors.New("invalid router info options: malformed coreVersion")
+	ErrInvalidRouterAddress   = errors.New("error validating router info: invalid router address")
+	ErrRouterInfoStale        = errors.New("error validating router info: published too long ago")
+	ErrRouterInfoFromFuture   = errors.New("error validating router info: published in the future")
+	ErrRouterInfoSignature    = errors.New("error validating router info: invalid signature")
+)
+
+// Option keys published in the options of a RouterInfo
+const (
+	ROUTER_INFO_OPTION_CAPS           = "caps"
+	ROUTER_INFO_OPTION_NET_ID         = "netId"
+	ROUTER_INFO_OPTION_ROUTER_VERSION = "router.version"
+	ROUTER_INFO_OPTION_CORE_VERSION   = "coreVersion"
+)
+
+// A host, port and transport style a peer can be dialed at.
+type DialTarget struct {
+	Transport string
+	Host      string
+	Port      int
+}
+
+// Reachability classes for a RouterInfo
+const (
+	REACHABILITY_UNKNOWN Reachability = iota
+	REACHABILITY_DIRECT
+	REACHABILITY_FIREWALLED
+)
+
+//
+// Assemble a RouterInfo from its components and sign it with signer.  The output
+// buffer is sized up front, including the signature size given by the RouterIdentity's
+// certificate, so neither the signed data nor the signature reallocate it.
+//
+func NewRouterInfo(
+	router_identity RouterIdentity,
+	published Date,
+	router_addresses []RouterAddress,
+	options Mapping,
+	signer crypto.Signer,
+) (router_info RouterInfo, err error) {
+	if len(router_addresses) > 255 {
+		log.WithFields(log.Fields{
+			"at":            "NewRouterInfo",
+			"address_count": len(router_addresses),
+			"reason":        "more than 255 router addresses",
+		}).Error("error creating router info")
+		err = errors.New("error creating router info: more than 255 router addresses")
+		return
+	}
+	size := len(router_identity) + len(published) + 1 + 1 + len(options) + KeysAndCert(router_identity).signatureSize()
+	for _, router_address := range router_addresses {
+		size += len(router_address)
+	}
+	data := make([]byte, 0, size)
+	data = append(data, router_identity...)
+	data = append(data, published[:]...)
+	data = append(data, byte(len(router_addresses)))
+	for _, router_address := range router_addresses {
+		data = append(data, router_address...)
+	}
+	// peer_size is unused and always zero
+	data = append(data, 0x00)
+	data = append(data, options...)
+	signature, err := signer.Sign(data)
+	if err != nil {
+		return
+	}
+	router_info = RouterInfo(append(data, signature...))
+	return
+}
+
+//
+// Assemble a RouterInfo whose bytes depend only on its arguments, for golden-file tests.
+// The RouterIdentity pairs public_key with the Ed25519 key of signing_private_key in a
+// Key Certificate and zero padding, the options are in canonical order and Ed25519
+// signatures are themselves deterministic.
+//
+func NewRouterInfoDeterministic(
+	public_key crypto.PublicKey,
+	signing_private_key crypto.Ed25519PrivateKey,
+	published time.Time,
+	router_addresses []RouterAddress,
+	options map[string]string,
+) (router_info RouterInfo, err error) {
+	signing_public_key, err := signing_private_key.Public()
+	if err != nil {
+		return
+	}
+	signer, err := signing_private_key.NewSigner()
+	if err != nil {
+		return
+	}
+	router_identity, err := NewEd25519RouterIdentity(public_key, signing_public_key)
+	if err != nil {
+		return
+	}
+	builder := NewMappingBuilder()
+	for key, value := range options {
+		builder.PutString(key, value)
+	}
+	mapping, err := builder.Build()
+	if err != nil {
+		return
+	}
+	router_info, err = NewRouterInfo(router_identity, NewDate(published), router_addresses, mapping, signer)
+	return
+}
+
+//
+// Read a RouterInfo from a slice of bytes, returning any extra data on the end of the slice
+// and any errors if a valid RouterInfo could not be read.  The address count, Mapping
+// sizes and total size are checked against limits before the data they describe is read,
+// and each options Mapping is parsed with ParseMappingLimited to bound its pair count.
+//
+func ReadRouterInfo(data []byte, limits ParseLimits) (router_info RouterInfo, remainder []byte, err error) {
+	router_identity, remaining, err := ReadRouterIdentity(data)
+	if err != nil {
+		return
+	}
+	if len(remaining) < 9 {
+		log.WithFields(log.Fields{
+			"at":           "ReadRouterInfo",
+			"data_len":     len(remaining),
+			"required_len": 9,
+			"reason":       "not enough data",
+		}).Error("error parsing router info")
+		err = errors.New("error parsing router addresses: not enough data")
+		return
+	}
+	addr_count, _, err := NewInteger(remaining[8:], 1)
+	if err != nil {
+		return
+	}
+	err = limits.check(addr_count, limits.maxAddresses(), "ReadRouterInfo", "router info", "too many router addresses")
+	if err != nil {
+		return
+	}
+	remaining = remaining[9:]
+	for i := 0; i < addr_count; i++ {
+		if err = limits.checkRouterAddressOptions(remaining); err != nil {
+			return
+		}
+		var router_address RouterAddress
+		if router_address, remaining, err = ReadRouterAddress(remaining); err != nil {
+			return
+		}
+		address_options, _ := router_address.Options()
+		if err = limits.checkMappingPairs(address_options, "ReadRouterInfo", "router address"); err != nil {
+			return
+		}
+	}
+	// peer_size followed by the size of the options Mapping
+	if len(remaining) < 3 {
+		log.WithFields(log.Fields{
+			"at":           "ReadRouterInfo",
+			"data_len":     len(remaining),
+			"required_len": 3,
+			"reason":       "not enough data",
+		}).Error("error parsing router info")
+		err = errors.New("error parsing router info: not enough data")
+		return
+	}
+	options_size, _, err := NewInteger(remaining[1:], 2)
+	if err != nil {
+		return
+	}
+	err = limits.check(options_size, limits.maxMappingSize(), "ReadRouterInfo", "router info", "options mapping too large")
+	if err != nil {
+		return
+	}
+	end := len(data) - len(remaining) + 3 + options_size + KeysAndCert(router_identity).signatureSize()
+	err = limits.check(end, limits.maxRouterInfoSize(), "ReadRouterInfo", "router info", "router info too large")
+	if err != nil {
+		return
+	}
+	if len(data) < end {
+		log.WithFields(log.Fields{
+			"at":           "ReadRouterInfo",
+			"data_len":     len(data),
+			"required_len": end,
+			"reason":       "not enough data",
+		}).Error("error parsing router info")
+		err = errors.New("error parsing router info: not enough data")
+		return
+	}
+	options_start := len(data) - len(remaining) + 1
+	options := Mapping(data[options_start : options_start+2+options_size])
+	if err = limits.checkMappingPairs(options, "ReadRouterInfo", "router info"); err != nil {
+		return
+	}
+	router_info = RouterInfo(data[:end])
+	remainder = data[end:]
+	return
+}
+
+//
+// Read a RouterInfo like ReadRouterInfo with the default limits, but return an error if
+// any data follows it, as when parsing a single RouterInfo file where trailing bytes
+// indicate corruption.
+//
+func ReadRouterInfoStrict(data []byte) (router_info RouterInfo, err error) {
+	router_info, remainder, err := ReadRouterInfo(data, DefaultParseLimits())
+	if err != nil {
+		return
+	}
+	if len(remainder) > 0 {
+		log.WithFields(log.Fields{
+			"at":            "ReadRouterInfoStrict",
+			"remainder_len": len(remainder),
+			"reason":        "data beyond end of router info",
+		}).Error("error parsing router info")
+		err = errors.New("error parsing router info: data beyond end of router info")
+		router_info = nil
+	}
+	return
+}
+
+//
+// Read a RouterInfo that may be stored gzip compressed, as in some netdb file formats.
+// Compressed data is detected by its gzip header and decompressed, reading no more than
+// the RouterInfo size limit, before the RouterInfo is read with ReadRouterInfo.  Any
+// data following the RouterInfo is ignored.
+//
+func ReadRouterInfoMaybeCompressed(data []byte, limits ParseLimits) (router_info RouterInfo, err error) {
+	max_size := limits.maxRouterInfoSize()
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, gerr := gzip.NewReader(bytes.NewReader(data))
+		if gerr != nil {
+			err = errors.New("error parsing router info: invalid gzip data")
+			return
+		}
+		data, gerr = io.ReadAll(io.LimitReader(reader, int64(max_size)+1))
+		if gerr != nil {
+			err = errors.New("error parsing router info: invalid gzip data")
+			return
+		}
+		if err = limits.check(len(data), max_size, "ReadRouterInfoMaybeCompressed", "router info", "router info too large"); err != nil {
+			return
+		}
+	}
+	router_info, _, err = ReadRouterInfo(data, limits)
+	return
+}
+
+//
+// Return this RouterInfo gzip compressed for storage.
+//
+func (router_info RouterInfo) Compress() (compressed []byte, err error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err = writer.Write(router_info); err != nil {
+		return
+	}
+	if err = writer.Close(); err != nil {
+		return
+	}
+	compressed = buf.Bytes()
+	return
+}
+
 //
 // Read a RouterIdentity from the RouterInfo, returning the RouterIdentity and any errors
 // encountered parsing the RouterIdentity.
@@ -102,6 +392,19 @@ func (router_info RouterInfo) IdentHash() (h Hash, err error) {
 	return
 }
 
+//
+// Calculate the Identity Hash of the RouterInfo serialized at the start of data, reading
+// only the leading RouterIdentity so the rest of the RouterInfo need not be present or
+// well formed.
+//
+func RouterInfoIdentityHash(data []byte) (h Hash, err error) {
+	router_identity, _, err := ReadRouterIdentity(data)
+	if err == nil {
+		h = HashData(router_identity)
+	}
+	return
+}
+
 //
 // Return the Date the RouterInfo was published and any errors encountered parsing the RouterInfo.
 //
@@ -125,6 +428,39 @@ func (router_info RouterInfo) Published() (date Date, err error) {
 	return
 }
 
+//
+// Return true if this RouterInfo was published after other.  A RouterInfo whose
+// published Date cannot be read is never newer, and is always older than one that can.
+//
+func (router_info RouterInfo) IsNewerThan(other RouterInfo) bool {
+	published, err := router_info.Published()
+	if err != nil {
+		return false
+	}
+	other_published, err := other.Published()
+	if err != nil {
+		return true
+	}
+	return published.Time().After(other_published.Time())
+}
+
+//
+// Return the RouterInfos published after cutoff, keeping their order.  RouterInfos
+// whose published Date cannot be read are dropped.
+//
+func FilterByPublishedAfter(router_infos []RouterInfo, cutoff time.Time) (filtered []RouterInfo) {
+	for _, router_info := range router_infos {
+		published, err := router_info.Published()
+		if err != nil {
+			continue
+		}
+		if published.Time().After(cutoff) {
+			filtered = append(filtered, router_info)
+		}
+	}
+	return
+}
+
 //
 // Return the Integer representing the number of RouterAddresses that are contained in this RouterInfo.
 //
@@ -144,7 +480,7 @@ func (router_info RouterInfo) RouterAddressCount() (count int, err error) {
 		err = errors.New("error parsing router addresses: not enough data")
 		return
 	}
-	count = Integer([]byte{remainder[8]})
+	count, _, err = NewInteger(remainder[8:], 1)
 	return
 }
 
@@ -184,6 +520,141 @@ func (router_info RouterInfo) RouterAddresses() (router_addresses []RouterAddres
 	return
 }
 
+//
+// Return the number of RouterAddresses in this RouterInfo, or zero if it cannot be read.
+//
+func (router_info RouterInfo) AddressCount() int {
+	count, _ := router_info.RouterAddressCount()
+	return count
+}
+
+//
+// Call fn with each RouterAddress in this RouterInfo in order, parsing them as they are
+// visited.  Iteration stops early when fn returns false or an address fails to parse.
+//
+func (router_info RouterInfo) EachAddress(fn func(RouterAddress) bool) {
+	_, remainder, err := ReadRouterIdentity(router_info)
+	if err != nil || len(remainder) < 9 {
+		return
+	}
+	addr_count, remaining, err := NewInteger(remainder[8:], 1)
+	if err != nil {
+		return
+	}
+	for i := 0; i < addr_count; i++ {
+		var router_address RouterAddress
+		router_address, remaining, err = ReadRouterAddress(remaining)
+		if err != nil || !fn(router_address) {
+			return
+		}
+	}
+}
+
+//
+// Return the transport styles of this RouterInfo's RouterAddresses in the order they
+// first appear, without duplicates.
+//
+func (router_info RouterInfo) TransportStyles() (styles []string) {
+	seen := make(map[string]bool)
+	router_info.EachAddress(func(router_address RouterAddress) bool {
+		style, err := router_address.TransportStyle()
+		if err != nil {
+			return true
+		}
+		style_str, _ := style.Data()
+		if !seen[style_str] {
+			seen[style_str] = true
+			styles = append(styles, style_str)
+		}
+		return true
+	})
+	return
+}
+
+//
+// Return the soonest expiration of this RouterInfo's RouterAddresses, ignoring addresses
+// with a null expiration.  Returns false if no address expires.
+//
+func (router_info RouterInfo) EarliestAddressExpiration() (earliest time.Time, expires bool) {
+	router_info.EachAddress(func(router_address RouterAddress) bool {
+		date, err := router_address.Expiration()
+		if err != nil || date == (Date{}) {
+			return true
+		}
+		expiration := date.Time()
+		if !expires || expiration.Before(earliest) {
+			earliest = expiration
+			expires = true
+		}
+		return true
+	})
+	return
+}
+
+//
+// Return a DialTarget for each of this RouterInfo's RouterAddresses that publishes a
+// host and a valid port, in the order the addresses appear.
+//
+func (router_info RouterInfo) DialTargets() (targets []DialTarget) {
+	router_info.EachAddress(func(router_address RouterAddress) bool {
+		style, err := router_address.TransportStyle()
+		if err != nil {
+			return true
+		}
+		host, err := router_address.Host()
+		if err != nil {
+			return true
+		}
+		port, err := router_address.Port()
+		if err != nil {
+			return true
+		}
+		style_str, _ := style.Data()
+		targets = append(targets, DialTarget{
+			Transport: style_str,
+			Host:      host,
+			Port:      port,
+		})
+		return true
+	})
+	return
+}
+
+//
+// Rebuild this RouterInfo without any byte-identical duplicate RouterAddresses,
+// keeping the first occurrence of each, and sign the result with signer.  A RouterInfo
+// too short to hold its options is an error.
+//
+func (router_info RouterInfo) DedupeAddresses(signer crypto.Signer) (deduped RouterInfo, err error) {
+	router_identity, err := router_info.RouterIdentity()
+	if err != nil {
+		return
+	}
+	published, err := router_info.Published()
+	if err != nil {
+		return
+	}
+	router_addresses, err := router_info.RouterAddresses()
+	if err != nil {
+		return
+	}
+	options, err := router_info.CheckedOptions()
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	unique := make([]RouterAddress, 0, len(router_addresses))
+	for _, router_address := range router_addresses {
+		if seen[string(router_address)] {
+			continue
+		}
+		seen[string(router_address)] = true
+		unique = append(unique, router_address)
+	}
+	deduped, err = NewRouterInfo(router_identity, published, unique, options, signer)
+	return
+}
+
 //
 // Return the PeerSize value, currently unused and always zero.
 //
@@ -194,32 +665,423 @@ func (router_info RouterInfo) PeerSize() int {
 }
 
 //
-// Return the Options Mapping inside this RouterInfo.
+// Return the Options Mapping inside this RouterInfo, or an empty Mapping if it cannot be
+// read.  Use CheckedOptions to tell a RouterInfo without options from a malformed one.
 //
 func (router_info RouterInfo) Options() (mapping Mapping) {
-	head := router_info.optionsLocation()
-	size := head + router_info.optionsSize()
-	mapping = Mapping(router_info[head:size])
+	mapping, _ = router_info.CheckedOptions()
 	return
 }
 
+//
+// Return the Options Mapping inside this RouterInfo, or an error if the RouterInfo is
+// too short to hold it.
+//
+func (router_info RouterInfo) CheckedOptions() (mapping Mapping, err error) {
+	head, err := router_info.optionsLocation()
+	if err != nil {
+		return
+	}
+	size, err := router_info.optionsSize()
+	if err != nil {
+		return
+	}
+	mapping = Mapping(router_info[head : head+size])
+	return
+}
+
+//
+// Classify how this router can be reached.  A router advertising the unreachable
+// 'U' capability or publishing only introducers is firewalled, a router with an
+// address carrying both a host and port is directly reachable.  A RouterInfo too
+// short to hold its options has unknown reachability.
+//
+func (router_info RouterInfo) Reachability() Reachability {
+	router_info_options, err := router_info.CheckedOptions()
+	if err != nil {
+		return REACHABILITY_UNKNOWN
+	}
+	if caps, present := router_info_options.Get(ROUTER_INFO_OPTION_CAPS); present && strings.ContainsRune(caps, 'U') {
+		return REACHABILITY_FIREWALLED
+	}
+	addresses, _ := router_info.RouterAddresses()
+	introduced := false
+	for _, address := range addresses {
+		options, err := address.Options()
+		if err != nil {
+			continue
+		}
+		_, has_host := options.Get(ROUTER_ADDRESS_OPTION_HOST)
+		_, has_port := options.Get(ROUTER_ADDRESS_OPTION_PORT)
+		if has_host && has_port {
+			return REACHABILITY_DIRECT
+		}
+		_, has_ssu_introducer := options.Get(fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_HOST, 0))
+		_, has_ssu2_introducer := options.Get(fmt.Sprintf(ROUTER_ADDRESS_OPTION_INTRODUCER_HASH, 0))
+		if has_ssu_introducer || has_ssu2_introducer {
+			introduced = true
+		}
+	}
+	if introduced {
+		return REACHABILITY_FIREWALLED
+	}
+	return REACHABILITY_UNKNOWN
+}
+
+//
+// Bandwidth classes, which lead the caps option ahead of the other capability flags.
+//
+const ROUTER_INFO_BANDWIDTH_CAPS = "KLMNOPX"
+
+//
+// Replace the caps option with the given capability flags, removing duplicates and
+// ordering them with the bandwidth classes first and the remaining flags compared
+// case-insensitively.  Changing the options invalidates the signature, which is
+// zeroed and must be replaced by signing the RouterInfo again.
+//
+func (router_info *RouterInfo) SetCapabilities(caps ...byte) (err error) {
+	seen := make(map[byte]bool)
+	var flags []byte
+	for _, c := range caps {
+		if !seen[c] {
+			seen[c] = true
+			flags = append(flags, c)
+		}
+	}
+	sort.SliceStable(flags, func(i, j int) bool {
+		bw_i := strings.IndexByte(ROUTER_INFO_BANDWIDTH_CAPS, flags[i]) >= 0
+		bw_j := strings.IndexByte(ROUTER_INFO_BANDWIDTH_CAPS, flags[j]) >= 0
+		if bw_i != bw_j {
+			return bw_i
+		}
+		lower_i, lower_j := unicode.ToLower(rune(flags[i])), unicode.ToLower(rune(flags[j]))
+		if lower_i != lower_j {
+			return lower_i < lower_j
+		}
+		return flags[i] < flags[j]
+	})
+	caps_str, err := ToI2PString(string(flags))
+	if err != nil {
+		return
+	}
+	if router_info.SignatureBytes() == nil {
+		err = errors.New("error setting capabilities: invalid router info")
+		return
+	}
+	head, err := router_info.optionsLocation()
+	if err != nil {
+		return
+	}
+	options, err := router_info.CheckedOptions()
+	if err != nil {
+		return
+	}
+	values := MappingValues{}
+	if len(options) >= 2 {
+		values, _ = options.Values()
+	}
+	kept := MappingValues{}
+	for _, kv_pair := range values {
+		if key, _ := kv_pair[0].Data(); key != ROUTER_INFO_OPTION_CAPS {
+			kept = append(kept, kv_pair)
+		}
+	}
+	caps_key, _ := ToI2PString(ROUTER_INFO_OPTION_CAPS)
+	kept = append(kept, [2]String{caps_key, caps_str})
+	mapping := ValuesToMapping(kept)
+	signature_size := len(router_info.SignatureBytes())
+	updated := make(RouterInfo, 0, head+len(mapping)+signature_size)
+	updated = append(updated, (*router_info)[:head]...)
+	updated = append(updated, mapping...)
+	updated = append(updated, make([]byte, signature_size)...)
+	*router_info = updated
+	return
+}
+
+//
+// Check that the options every published RouterInfo must carry are present and
+// well formed: a numeric netId and a dotted numeric router.version (or the
+// older coreVersion).  A RouterInfo too short to hold its options is an error.
+//
+func (router_info RouterInfo) ValidateRequiredOptions() (err error) {
+	options, err := router_info.CheckedOptions()
+	if err != nil {
+		return
+	}
+	net_id, present := options.Get(ROUTER_INFO_OPTION_NET_ID)
+	if !present {
+		err = ErrMissingNetID
+	} else if _, perr := strconv.Atoi(net_id); perr != nil {
+		err = ErrNonNumericNetID
+	} else if version, present := options.Get(ROUTER_INFO_OPTION_ROUTER_VERSION); present {
+		if !validVersionString(version) {
+			err = ErrMalformedRouterVersion
+		}
+	} else if version, present := options.Get(ROUTER_INFO_OPTION_CORE_VERSION); present {
+		if !validVersionString(version) {
+			err = ErrMalformedCoreVersion
+		}
+	} else {
+		err = ErrMissingRouterVersion
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"at":     "(RouterInfo) ValidateRequiredOptions",
+			"reason": err.Error(),
+		}).Error("invalid router info")
+	}
+	return
+}
+
+//
+// Check that a version string is a dot separated list of numbers, such as 0.9.24.
+//
+func validVersionString(version string) bool {
+	if version == "" {
+		return false
+	}
+	for _, part := range strings.Split(version, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+//
+// Verify the RouterInfo's Signature with its RouterIdentity's SigningPublicKey, returning
+// nil if the signature is valid.  The data after the options must be exactly one
+// Signature of the signing key's type, so a RouterInfo whose signature length does not
+// match its Key Certificate is rejected before any key is trusted.
+//
+func (router_info RouterInfo) Verify() (err error) {
+	router_identity, err := router_info.RouterIdentity()
+	if err != nil {
+		return
+	}
+	head, err := router_info.optionsLocation()
+	if err != nil {
+		return
+	}
+	size, err := router_info.optionsSize()
+	if err != nil {
+		return
+	}
+	signed_len := head + size
+	signature_len := KeysAndCert(router_identity).signatureSize()
+	if len(router_info)-signed_len != signature_len {
+		log.WithFields(log.Fields{
+			"at":               "(RouterInfo) Verify",
+			"signature_len":    len(router_info) - signed_len,
+			"required_sig_len": signature_len,
+			"reason":           "signature length does not match signing key type",
+		}).Error("error verifying router info")
+		err = errors.New("error verifying router info: signature length does not match signing key type")
+		return
+	}
+	signature := router_info.SignatureBytes()
+	if signature == nil {
+		err = errors.New("error verifying router info: not enough data")
+		return
+	}
+	spk, err := router_identity.SigningPublicKey()
+	if err != nil {
+		return
+	}
+	if spk == nil {
+		err = errors.New("error verifying router info: unsupported signing key type")
+		return
+	}
+	verifier, err := spk.NewVerifier()
+	if err != nil {
+		return
+	}
+	err = verifier.Verify(router_info[:signed_len], signature)
+	return
+}
+
+//
+// Run every validity check on the RouterInfo, returning the error of the first that
+// fails: the length, the required options, each RouterAddress's transport options,
+// staleness against now, a publication date too far after now, and finally the Signature.  Every error matches one of the
+// exported sentinel errors above with errors.Is.
+//
+func (router_info RouterInfo) Validate(now time.Time) (err error) {
+	if router_info.SignatureBytes() == nil {
+		err = ErrRouterInfoTooShort
+		return
+	}
+	if err = router_info.ValidateRequiredOptions(); err != nil {
+		return
+	}
+	router_addresses, err := router_info.RouterAddresses()
+	if err != nil {
+		err = validationError{ErrInvalidRouterAddress, err}
+		return
+	}
+	for _, router_address := range router_addresses {
+		if err = router_address.ValidateForTransport(); err != nil {
+			err = validationError{ErrInvalidRouterAddress, err}
+			return
+		}
+	}
+	published, err := router_info.Published()
+	if err != nil {
+		return
+	}
+	if now.Sub(published.Time()) > ROUTER_INFO_MAX_AGE {
+		log.WithFields(log.Fields{
+			"at":        "(RouterInfo) Validate",
+			"published": published.Time(),
+			"reason":    "published too long ago",
+		}).Error("invalid router info")
+		err = ErrRouterInfoStale
+		return
+	}
+	if published.Time().Sub(now) > ROUTER_INFO_MAX_CLOCK_SKEW {
+		log.WithFields(log.Fields{
+			"at":        "(RouterInfo) Validate",
+			"published": published.Time(),
+			"reason":    "published in the future",
+		}).Error("invalid router info")
+		err = ErrRouterInfoFromFuture
+		return
+	}
+	if err = router_info.Verify(); err != nil {
+		err = validationError{ErrRouterInfoSignature, err}
+	}
+	return
+}
+
+//
+// An error from a check run by Validate, which keeps the message of the underlying
+// error but matches kind with errors.Is.
+//
+type validationError struct {
+	kind error
+	err  error
+}
+
+func (validation_error validationError) Error() string {
+	return validation_error.err.Error()
+}
+
+func (validation_error validationError) Is(target error) bool {
+	return target == validation_error.kind
+}
+
+func (validation_error validationError) Unwrap() error {
+	return validation_error.err
+}
+
+//
+// Return a readable JSON object describing this RouterInfo for tooling, with its Identity
+// Hash in base32, its published time, capabilities and options, and each RouterAddress
+// with its options decoded.  Key material is not included.
+//
+func (router_info RouterInfo) MarshalJSON() (data []byte, err error) {
+	ident_hash, err := router_info.IdentHash()
+	if err != nil {
+		return
+	}
+	published, err := router_info.Published()
+	if err != nil {
+		return
+	}
+	addresses, err := router_info.RouterAddresses()
+	if err != nil {
+		return
+	}
+	options, err := router_info.CheckedOptions()
+	if err != nil {
+		return
+	}
+	capabilities, _ := options.Get(ROUTER_INFO_OPTION_CAPS)
+	object := routerInfoJSON{
+		IdentHash:    strings.TrimRight(base32.EncodeToString(ident_hash[:]), "="),
+		Published:    published.Time().UTC(),
+		Capabilities: capabilities,
+		Addresses:    []routerAddressJSON{},
+		Options:      options.toGoMap(),
+	}
+	for _, address := range addresses {
+		cost, _ := address.Cost()
+		style, _ := address.TransportStyle()
+		style_str, _ := style.Data()
+		address_options, _ := address.Options()
+		object.Addresses = append(object.Addresses, routerAddressJSON{
+			TransportStyle: style_str,
+			Cost:           cost,
+			Options:        address_options.toGoMap(),
+		})
+	}
+	data, err = json.Marshal(object)
+	return
+}
+
+// The JSON form of a RouterInfo written by MarshalJSON.
+type routerInfoJSON struct {
+	IdentHash    string              `json:"ident_hash"`
+	Published    time.Time           `json:"published"`
+	Capabilities string              `json:"capabilities"`
+	Addresses    []routerAddressJSON `json:"addresses"`
+	Options      map[string]string   `json:"options"`
+}
+
+// The JSON form of a RouterAddress inside a routerInfoJSON.
+type routerAddressJSON struct {
+	TransportStyle string            `json:"transport_style"`
+	Cost           int               `json:"cost"`
+	Options        map[string]string `json:"options"`
+}
+
 //
 // Return the signature of this router info
 //
 func (router_info RouterInfo) Signature() (signature Signature) {
-	head := router_info.optionsLocation()
-	size := head + router_info.optionsSize()
-	ident, _ := router_info.RouterIdentity()
-	keyCert := KeyCertificate(ident)
-	sigSize := keyCert.SignatureSize()
-	signature = Signature(router_info[size : size+sigSize])
+	signature = Signature(router_info.SignatureBytes())
 	return
 }
 
 //
-// Used during parsing to determine where in the RouterInfo the Mapping data begins.
+// Return the raw signature trailing this RouterInfo, with its length determined by the
+// RouterIdentity's signing key type, or nil if the RouterInfo is too short to hold it.
 //
-func (router_info RouterInfo) optionsLocation() (location int) {
+func (router_info RouterInfo) SignatureBytes() (signature []byte) {
+	ident, err := router_info.RouterIdentity()
+	if err != nil {
+		return
+	}
+	head, err := router_info.optionsLocation()
+	if err != nil {
+		return
+	}
+	size, err := router_info.optionsSize()
+	if err != nil {
+		return
+	}
+	start := head + size
+	end := start + KeysAndCert(ident).signatureSize()
+	router_info_len := len(router_info)
+	if router_info_len < end {
+		log.WithFields(log.Fields{
+			"at":           "(RouterInfo) SignatureBytes",
+			"data_len":     router_info_len,
+			"required_len": end,
+			"reason":       "not enough data",
+		}).Error("error parsing router info")
+		return
+	}
+	signature = router_info[start:end]
+	return
+}
+
+//
+// Used during parsing to determine where in the RouterInfo the Mapping data begins,
+// returning an error if the RouterIdentity, RouterAddresses or peer_size before it
+// cannot be read.
+//
+func (router_info RouterInfo) optionsLocation() (location int, err error) {
 	data, remainder, err := ReadRouterIdentity(router_info)
 	if err != nil {
 		return
@@ -239,30 +1101,56 @@ func (router_info RouterInfo) optionsLocation() (location int) {
 	}
 	location += 9
 
-	remaining := remainder[9:]
-	var router_address RouterAddress
-	var router_addresses []RouterAddress
-	addr_count, cerr := router_info.RouterAddressCount()
-	if cerr != nil {
-		err = cerr
+	addr_count, remaining, err := NewInteger(remainder[8:], 1)
+	if err != nil {
 		return
 	}
 	for i := 0; i < addr_count; i++ {
+		var router_address RouterAddress
 		router_address, remaining, err = ReadRouterAddress(remaining)
-		if err == nil {
-			location += len(router_address)
-			router_addresses = append(router_addresses, router_address)
+		if err != nil {
+			return
 		}
+		location += len(router_address)
+	}
+	if len(remaining) < 1 {
+		log.WithFields(log.Fields{
+			"at":     "(RouterInfo) optionsLocation",
+			"reason": "missing peer_size",
+		}).Error("error parsing router info")
+		err = errors.New("error parsing router info: not enough data")
+		return
 	}
 	location += 1
 	return
 }
 
 //
-// Used during parsing to determine the size of the options in the RouterInfo.
+// Used during parsing to determine the size of the options in the RouterInfo, including
+// the two byte size, returning an error if the Mapping is not entirely present.
 //
-func (router_info RouterInfo) optionsSize() (size int) {
-	head := router_info.optionsLocation()
-	size = Integer(router_info[head:head+2]) + 2
+func (router_info RouterInfo) optionsSize() (size int, err error) {
+	head, err := router_info.optionsLocation()
+	if err != nil {
+		return
+	}
+	router_info_len := len(router_info)
+	if router_info_len >= head+2 {
+		size, _, err = NewInteger(router_info[head:], 2)
+		if err != nil {
+			return
+		}
+		size += 2
+	}
+	if size == 0 || router_info_len < head+size {
+		log.WithFields(log.Fields{
+			"at":           "(RouterInfo) optionsSize",
+			"data_len":     router_info_len,
+			"required_len": head + size,
+			"reason":       "not enough data",
+		}).Error("error parsing router info")
+		err = errors.New("error parsing router info: not enough data")
+		size = 0
+	}
 	return
 }
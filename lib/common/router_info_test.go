@@ -2,9 +2,18 @@ package common
 
 import (
 	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/go-i2p/go-i2p/lib/common/base32"
+	"github.com/go-i2p/go-i2p/lib/crypto"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
 func buildRouterIdentity() RouterIdentity {
@@ -23,14 +32,6 @@ func buildMapping() Mapping {
 	return mapping
 }
 
-func buildRouterAddress(transport string) RouterAddress {
-	router_address_bytes := []byte{0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-	str, _ := ToI2PString(transport)
-	router_address_bytes = append(router_address_bytes, []byte(str)...)
-	router_address_bytes = append(router_address_bytes, buildMapping()...)
-	return RouterAddress(router_address_bytes)
-}
-
 func buildFullRouterInfo() RouterInfo {
 	router_info_data := make([]byte, 0)
 	router_info_data = append(router_info_data, buildRouterIdentity()...)
@@ -39,7 +40,7 @@ func buildFullRouterInfo() RouterInfo {
 	router_info_data = append(router_info_data, buildRouterAddress("foo")...)
 	router_info_data = append(router_info_data, 0x00)
 	router_info_data = append(router_info_data, buildMapping()...)
-	router_info_data = append(router_info_data, make([]byte, 40)...)
+	router_info_data = append(router_info_data, make([]byte, signature_sizes[KEYCERT_SIGN_P256])...)
 	return RouterInfo(router_info_data)
 }
 
@@ -124,7 +125,7 @@ func TestRouterAddressesReturnsAddressesWithMultiple(t *testing.T) {
 	router_info_data = append(router_info_data, buildRouterAddress("foo2")...)
 	router_info_data = append(router_info_data, 0x00)
 	router_info_data = append(router_info_data, buildMapping()...)
-	router_info_data = append(router_info_data, make([]byte, 40)...)
+	router_info_data = append(router_info_data, make([]byte, signature_sizes[KEYCERT_SIGN_P256])...)
 	router_info := RouterInfo(router_info_data)
 
 	count, err := router_info.RouterAddressCount()
@@ -172,7 +173,7 @@ func TestSignatureIsCorrectSize(t *testing.T) {
 
 	router_info := buildFullRouterInfo()
 	signature := router_info.Signature()
-	assert.Equal(40, len(signature))
+	assert.Equal(signature_sizes[KEYCERT_SIGN_P256], len(signature))
 }
 
 func TestRouterIdentityIsCorrect(t *testing.T) {
@@ -189,3 +190,938 @@ func TestRouterIdentityIsCorrect(t *testing.T) {
 		),
 	)
 }
+
+func TestReachabilityIsDirectWithHostAndPort(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	assert.Equal(REACHABILITY_DIRECT, router_info.Reachability())
+}
+
+func TestReachabilityIsFirewalledWithOnlyIntroducers(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info_data := make([]byte, 0)
+	router_info_data = append(router_info_data, buildRouterIdentity()...)
+	router_info_data = append(router_info_data, buildDate()...)
+	router_info_data = append(router_info_data, 0x01)
+	router_info_data = append(router_info_data, buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"ih0":   buildKeyString(0x03),
+		"itag0": "1234",
+	})...)
+	router_info_data = append(router_info_data, 0x00)
+	router_info_data = append(router_info_data, buildMapping()...)
+	router_info_data = append(router_info_data, make([]byte, signature_sizes[KEYCERT_SIGN_P256])...)
+	router_info := RouterInfo(router_info_data)
+
+	assert.Equal(REACHABILITY_FIREWALLED, router_info.Reachability())
+}
+
+func TestReachabilityIsUnknownWhenTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	for length := 0; length < len(router_info)-signature_sizes[KEYCERT_SIGN_P256]; length++ {
+		assert.Equal(REACHABILITY_UNKNOWN, router_info[:length].Reachability(), "length %d", length)
+	}
+}
+
+func buildRouterInfoWithOptions(options map[string]string) RouterInfo {
+	mapping, _ := GoMapToMapping(options)
+	router_info_data := make([]byte, 0)
+	router_info_data = append(router_info_data, buildRouterIdentity()...)
+	router_info_data = append(router_info_data, buildDate()...)
+	router_info_data = append(router_info_data, 0x01)
+	router_info_data = append(router_info_data, buildRouterAddress("foo")...)
+	router_info_data = append(router_info_data, 0x00)
+	router_info_data = append(router_info_data, mapping...)
+	router_info_data = append(router_info_data, make([]byte, signature_sizes[KEYCERT_SIGN_P256])...)
+	return RouterInfo(router_info_data)
+}
+
+func TestValidateRequiredOptionsAcceptsValidOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"netId": "2", "router.version": "0.9.24"})
+	assert.Nil(router_info.ValidateRequiredOptions())
+}
+
+func TestValidateRequiredOptionsReportsMissingNetId(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"router.version": "0.9.24"})
+	err := router_info.ValidateRequiredOptions()
+	if assert.NotNil(err) {
+		assert.Equal("invalid router info options: missing netId", err.Error())
+		assert.True(errors.Is(err, ErrMissingNetID))
+	}
+}
+
+func TestValidateRequiredOptionsReportsMalformedVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"netId": "2", "coreVersion": "0.9.x"})
+	err := router_info.ValidateRequiredOptions()
+	if assert.NotNil(err) {
+		assert.Equal("invalid router info options: malformed coreVersion", err.Error())
+		assert.True(errors.Is(err, ErrMalformedCoreVersion))
+	}
+}
+
+// signer producing a fixed all zero signature sized for the P256 key of buildRouterIdentity
+type zeroSigner struct{}
+
+func (zeroSigner) Sign(data []byte) ([]byte, error) {
+	return make([]byte, signature_sizes[KEYCERT_SIGN_P256]), nil
+}
+
+func (zeroSigner) SignHash(h []byte) ([]byte, error) {
+	return make([]byte, signature_sizes[KEYCERT_SIGN_P256]), nil
+}
+
+// naive RouterInfo assembly growing the buffer one field at a time, the baseline for NewRouterInfo
+func naiveRouterInfo(router_identity RouterIdentity, published Date, router_addresses []RouterAddress, options Mapping, signer crypto.Signer) (RouterInfo, error) {
+	var data []byte
+	data = append(data, router_identity...)
+	data = append(data, published[:]...)
+	data = append(data, byte(len(router_addresses)))
+	for _, router_address := range router_addresses {
+		data = append(data, router_address...)
+	}
+	data = append(data, 0x00)
+	data = append(data, options...)
+	signature, err := signer.Sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return RouterInfo(append(data, signature...)), nil
+}
+
+func TestNewRouterInfoMatchesNaiveAssembly(t *testing.T) {
+	assert := assert.New(t)
+
+	var published Date
+	copy(published[:], buildDate())
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		published,
+		[]RouterAddress{buildRouterAddress("foo")},
+		buildMapping(),
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	assert.Equal([]byte(buildFullRouterInfo()), []byte(router_info), "NewRouterInfo() did not match the naively assembled RouterInfo")
+}
+
+func TestNewRouterInfoMatchesNaiveBaseline(t *testing.T) {
+	assert := assert.New(t)
+
+	var published Date
+	copy(published[:], buildDate())
+	for _, router_addresses := range [][]RouterAddress{
+		nil,
+		{buildRouterAddress("NTCP2")},
+		{buildRouterAddress("NTCP2"), buildRouterAddress("SSU2")},
+	} {
+		router_info, err := NewRouterInfo(buildRouterIdentity(), published, router_addresses, buildMapping(), zeroSigner{})
+		assert.Nil(err)
+		baseline, err := naiveRouterInfo(buildRouterIdentity(), published, router_addresses, buildMapping(), zeroSigner{})
+		assert.Nil(err)
+		assert.Equal([]byte(baseline), []byte(router_info), "NewRouterInfo() did not match the naive baseline with %d addresses", len(router_addresses))
+	}
+}
+
+func TestNewRouterInfoRejectsTooManyAddresses(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewRouterInfo(buildRouterIdentity(), Date{}, make([]RouterAddress, 256), buildMapping(), zeroSigner{})
+	if assert.NotNil(err) {
+		assert.Equal("error creating router info: more than 255 router addresses", err.Error())
+	}
+}
+
+func benchmarkRouterInfoBytes(b *testing.B, assemble func(RouterIdentity, Date, []RouterAddress, Mapping, crypto.Signer) (RouterInfo, error)) {
+	router_identity := buildRouterIdentity()
+	var published Date
+	copy(published[:], buildDate())
+	router_addresses := []RouterAddress{
+		buildRouterAddress("NTCP2"),
+		buildRouterAddress("SSU2"),
+	}
+	options := buildMapping()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		_, err := assemble(router_identity, published, router_addresses, options, zeroSigner{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRouterInfoBytes(b *testing.B) {
+	benchmarkRouterInfoBytes(b, NewRouterInfo)
+}
+
+func BenchmarkRouterInfoBytesNaive(b *testing.B) {
+	benchmarkRouterInfoBytes(b, naiveRouterInfo)
+}
+
+func TestDedupeAddressesCollapsesIdenticalAddresses(t *testing.T) {
+	assert := assert.New(t)
+
+	var published Date
+	copy(published[:], buildDate())
+	router_info, _ := NewRouterInfo(
+		buildRouterIdentity(),
+		published,
+		[]RouterAddress{buildRouterAddress("NTCP2"), buildRouterAddress("NTCP2"), buildRouterAddress("SSU2")},
+		buildMapping(),
+		zeroSigner{},
+	)
+	deduped, err := router_info.DedupeAddresses(zeroSigner{})
+	assert.Nil(err)
+	router_addresses, err := deduped.RouterAddresses()
+	assert.Nil(err)
+	if assert.Equal(2, len(router_addresses)) {
+		assert.Equal([]byte(buildRouterAddress("NTCP2")), []byte(router_addresses[0]))
+		assert.Equal([]byte(buildRouterAddress("SSU2")), []byte(router_addresses[1]))
+	}
+	assert.Equal([]byte(buildMapping()), []byte(deduped.Options()))
+}
+
+func TestDedupeAddressesReportsTruncatedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	for length := 0; length < len(router_info)-signature_sizes[KEYCERT_SIGN_P256]; length++ {
+		deduped, err := router_info[:length].DedupeAddresses(zeroSigner{})
+		assert.NotNil(err, "length %d", length)
+		assert.Nil(deduped, "length %d", length)
+	}
+}
+
+func TestSignatureBytesIsEd25519Size(t *testing.T) {
+	assert := assert.New(t)
+
+	router_ident_data := make([]byte, 128+256)
+	router_ident_data = append(router_ident_data, []byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x00}...)
+	router_info_data := make([]byte, 0)
+	router_info_data = append(router_info_data, router_ident_data...)
+	router_info_data = append(router_info_data, buildDate()...)
+	router_info_data = append(router_info_data, 0x01)
+	router_info_data = append(router_info_data, buildRouterAddress("foo")...)
+	router_info_data = append(router_info_data, 0x00)
+	router_info_data = append(router_info_data, buildMapping()...)
+	router_info_data = append(router_info_data, buildSignature(64)...)
+	router_info := RouterInfo(router_info_data)
+
+	signature := router_info.SignatureBytes()
+	assert.Equal(buildSignature(64), signature)
+}
+
+func TestSignatureBytesIsNilWhenTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	router_info = router_info[:len(router_info)-1]
+	assert.Nil(router_info.SignatureBytes())
+}
+
+func TestRouterInfoRoundTripsThroughGzip(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	compressed, err := router_info.Compress()
+	assert.Nil(err)
+	assert.NotEqual([]byte(router_info), compressed)
+	decompressed, err := ReadRouterInfoMaybeCompressed(compressed, DefaultParseLimits())
+	assert.Nil(err)
+	assert.Equal(router_info, decompressed)
+}
+
+func TestReadRouterInfoMaybeCompressedReadsUncompressed(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	read, err := ReadRouterInfoMaybeCompressed(router_info, DefaultParseLimits())
+	assert.Nil(err)
+	assert.Equal(router_info, read)
+}
+
+func TestReadRouterInfoMaybeCompressedReportsTruncation(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	compressed, err := router_info[:len(router_info)-1].Compress()
+	assert.Nil(err)
+	_, err = ReadRouterInfoMaybeCompressed(compressed, DefaultParseLimits())
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: not enough data", err.Error())
+	}
+}
+
+func TestReadRouterInfoMaybeCompressedLimitsDecompressedSize(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(make([]byte, PARSE_LIMIT_MAX_ROUTER_INFO_SIZE+1))
+	assert.Nil(err)
+	assert.Nil(writer.Close())
+	read, err := ReadRouterInfoMaybeCompressed(buf.Bytes(), DefaultParseLimits())
+	assert.Nil(read)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: router info too large", err.Error())
+	}
+}
+
+func TestReadRouterInfoMaybeCompressedAppliesLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	compressed, err := router_info.Compress()
+	assert.Nil(err)
+	limits := DefaultParseLimits()
+	limits.MaxRouterInfoSize = len(router_info) - 1
+	for _, data := range [][]byte{router_info, compressed} {
+		_, err = ReadRouterInfoMaybeCompressed(data, limits)
+		if assert.NotNil(err) {
+			assert.Equal("error parsing router info: router info too large", err.Error())
+		}
+	}
+}
+
+// NewRouterInfoDeterministic output for the keys and options in
+// TestNewRouterInfoDeterministicMatchesGolden, with the zero padding between
+// the X25519 and Ed25519 keys elided.
+var golden_router_info_hex = "ce8d3ad1ccb633ec7b70c17814a5c76ecd029685050d344745ba05870e587d59" +
+	strings.Repeat("00", KEYS_AND_CERT_DATA_SIZE-KEYCERT_CRYPTO_X25519_SIZE-KEYCERT_SIGN_ED25519_SIZE) +
+	"8a88e3dd7409f195fd52db2d3cba5d72ca6709bf1d94121bf3748801b40f6f5c" +
+	"05000400070004" +
+	"00000174876e8000" +
+	"01" +
+	"060000000000000000054e54435032001d04686f73743d093132372e302e302e313b04706f72743d04343536373b" +
+	"00" +
+	"002c04636170733d024c523b056e657449643d01323b0e726f757465722e76657273696f6e3d06302e392e34383b" +
+	"35d50269f8737b4c5141bc375f13485a8ad8d0d1b9f71faf22c37d7445a2ee4c" +
+	"139a53836c827907624c09def0f7dc640d78ff69e7069a67537a9ecbe179410d"
+
+func TestNewRouterInfoDeterministicMatchesGolden(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = 0x01
+	}
+	var encryption_private_key crypto.X25519PrivateKey
+	for i := range encryption_private_key {
+		encryption_private_key[i] = 0x02
+	}
+	encryption_key, err := encryption_private_key.Public()
+	assert.Nil(err)
+	options := map[string]string{"netId": "2", "router.version": "0.9.48", "caps": "LR"}
+
+	router_info, err := NewRouterInfoDeterministic(
+		encryption_key,
+		crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(seed)),
+		time.Unix(1600000000, 0),
+		[]RouterAddress{buildRouterAddress("NTCP2")},
+		options,
+	)
+	assert.Nil(err)
+	assert.Equal(golden_router_info_hex, hex.EncodeToString(router_info))
+
+	again, err := NewRouterInfoDeterministic(
+		encryption_key,
+		crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(seed)),
+		time.Unix(1600000000, 0),
+		[]RouterAddress{buildRouterAddress("NTCP2")},
+		options,
+	)
+	assert.Nil(err)
+	assert.Equal(router_info, again)
+}
+
+func TestEachAddressStopsEarly(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		Date{},
+		[]RouterAddress{buildRouterAddress("NTCP2"), buildRouterAddress("SSU2"), buildRouterAddress("SSU")},
+		buildMapping(),
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	assert.Equal(3, router_info.AddressCount())
+
+	visited := []string{}
+	router_info.EachAddress(func(router_address RouterAddress) bool {
+		style, _ := router_address.TransportStyle()
+		style_str, _ := style.Data()
+		visited = append(visited, style_str)
+		return style_str != "SSU2"
+	})
+	assert.Equal([]string{"NTCP2", "SSU2"}, visited)
+}
+
+func TestAddressCountIsZeroWhenTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	assert.Equal(0, router_info[:KEYS_AND_CERT_MIN_SIZE].AddressCount())
+}
+
+func TestTransportStylesAreDeduplicated(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		Date{},
+		[]RouterAddress{buildRouterAddress("NTCP2"), buildRouterAddress("SSU2"), buildRouterAddress("NTCP2")},
+		buildMapping(),
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	assert.Equal([]string{"NTCP2", "SSU2"}, router_info.TransportStyles())
+}
+
+func TestDialTargetsSkipsAddressesWithoutHostAndPort(t *testing.T) {
+	assert := assert.New(t)
+
+	hidden := buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{"caps": "4"})
+
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		Date{},
+		[]RouterAddress{hidden, buildRouterAddress("NTCP2")},
+		buildMapping(),
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	assert.Equal([]DialTarget{{Transport: "NTCP2", Host: "127.0.0.1", Port: 4567}}, router_info.DialTargets())
+}
+
+func TestSetCapabilitiesOrdersAndDedupes(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"caps": "XR", "netId": "2"})
+	copy(router_info[len(router_info)-signature_sizes[KEYCERT_SIGN_P256]:], buildSignature(signature_sizes[KEYCERT_SIGN_P256]))
+	err := router_info.SetCapabilities('R', 'f', 'L', 'R')
+	assert.Nil(err)
+
+	caps, present := router_info.Options().Get("caps")
+	assert.True(present)
+	assert.Equal("LfR", caps)
+	net_id, present := router_info.Options().Get("netId")
+	assert.True(present)
+	assert.Equal("2", net_id)
+	assert.Equal(1, router_info.AddressCount())
+	assert.Equal(make([]byte, signature_sizes[KEYCERT_SIGN_P256]), router_info.SignatureBytes(), "SetCapabilities() did not invalidate the signature")
+}
+
+func TestSetCapabilitiesReportsTruncatedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	full := buildFullRouterInfo()
+	for length := 0; length < len(full)-signature_sizes[KEYCERT_SIGN_P256]; length++ {
+		router_info := append(RouterInfo{}, full[:length]...)
+		err := router_info.SetCapabilities('R')
+		assert.NotNil(err, "length %d", length)
+		assert.Equal(full[:length], router_info, "length %d", length)
+	}
+}
+
+func TestVerifyAcceptsSignedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := make([]byte, ed25519.SeedSize)
+	var encryption_key crypto.X25519PublicKey
+	router_info, err := NewRouterInfoDeterministic(
+		encryption_key,
+		crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(seed)),
+		time.Unix(1600000000, 0),
+		[]RouterAddress{buildRouterAddress("NTCP2")},
+		map[string]string{"netId": "2"},
+	)
+	assert.Nil(err)
+	assert.Nil(router_info.Verify(), "Verify() rejected a correctly signed RouterInfo")
+
+	router_identity, _ := router_info.RouterIdentity()
+	router_info[len(router_identity)] ^= 0xff
+	assert.NotNil(router_info.Verify(), "Verify() accepted a tampered RouterInfo")
+}
+
+func TestFilterByPublishedAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	cutoff := time.Unix(1600000000, 0)
+	var router_infos []RouterInfo
+	for _, published := range []time.Time{
+		cutoff.Add(-time.Hour),
+		cutoff.Add(time.Hour),
+		cutoff,
+		cutoff.Add(time.Minute),
+	} {
+		router_info, err := NewRouterInfo(buildRouterIdentity(), NewDate(published), nil, buildMapping(), zeroSigner{})
+		assert.Nil(err)
+		router_infos = append(router_infos, router_info)
+	}
+	router_infos = append(router_infos, RouterInfo(buildRouterIdentity()[:100]))
+
+	filtered := FilterByPublishedAfter(router_infos, cutoff)
+	if assert.Equal(2, len(filtered)) {
+		assert.Equal(router_infos[1], filtered[0])
+		assert.Equal(router_infos[3], filtered[1])
+	}
+}
+
+func TestIsNewerThanComparesPublished(t *testing.T) {
+	assert := assert.New(t)
+
+	published := time.Unix(1600000000, 0)
+	older, err := NewRouterInfo(buildRouterIdentity(), NewDate(published), nil, buildMapping(), zeroSigner{})
+	assert.Nil(err)
+	newer, err := NewRouterInfo(buildRouterIdentity(), NewDate(published.Add(time.Second)), nil, buildMapping(), zeroSigner{})
+	assert.Nil(err)
+	truncated := RouterInfo(buildRouterIdentity()[:100])
+
+	assert.True(newer.IsNewerThan(older))
+	assert.False(older.IsNewerThan(newer))
+	assert.False(older.IsNewerThan(older))
+	assert.True(older.IsNewerThan(truncated))
+	assert.False(truncated.IsNewerThan(older))
+}
+
+func TestRouterInfoIdentityHashMatchesIdentHash(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	expected, err := router_info.IdentHash()
+	assert.Nil(err)
+
+	h, err := RouterInfoIdentityHash(router_info)
+	assert.Nil(err)
+	assert.Equal(expected, h)
+
+	h, err = RouterInfoIdentityHash(router_info[:len(buildRouterIdentity())])
+	assert.Nil(err)
+	assert.Equal(expected, h)
+
+	_, err = RouterInfoIdentityHash(router_info[:KEYS_AND_CERT_MIN_SIZE-1])
+	assert.NotNil(err)
+}
+
+func TestEarliestAddressExpiration(t *testing.T) {
+	assert := assert.New(t)
+
+	soon := time.Unix(1600000000, 0)
+	later := soon.Add(time.Hour)
+	never := buildRouterAddress("SSU2")
+	expiring_later := buildRouterAddress("NTCP2")
+	later_date := NewDate(later)
+	copy(expiring_later[1:ROUTER_ADDRESS_MIN_SIZE], later_date[:])
+	expiring_soon := buildRouterAddress("NTCP2")
+	soon_date := NewDate(soon)
+	copy(expiring_soon[1:ROUTER_ADDRESS_MIN_SIZE], soon_date[:])
+
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		Date{},
+		[]RouterAddress{never, expiring_later, expiring_soon},
+		buildMapping(),
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	earliest, expires := router_info.EarliestAddressExpiration()
+	assert.True(expires)
+	assert.True(soon.Equal(earliest))
+
+	router_info, err = NewRouterInfo(buildRouterIdentity(), Date{}, []RouterAddress{never}, buildMapping(), zeroSigner{})
+	assert.Nil(err)
+	_, expires = router_info.EarliestAddressExpiration()
+	assert.False(expires)
+}
+
+// build a RouterInfo that passes every check in Validate at time.Unix(1600000000, 0)
+func buildValidRouterInfo(t *testing.T) RouterInfo {
+	seed := make([]byte, ed25519.SeedSize)
+	var encryption_key crypto.X25519PublicKey
+	key := buildKeyString(0x01)
+	router_info, err := NewRouterInfoDeterministic(
+		encryption_key,
+		crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(seed)),
+		time.Unix(1600000000, 0),
+		[]RouterAddress{buildRouterAddressWithOptions(NTCP2_TRANSPORT_STYLE, map[string]string{
+			"host": "10.0.0.1",
+			"port": "12345",
+			"s":    key,
+			"i":    key,
+			"v":    "2",
+		})},
+		map[string]string{"netId": "2", "router.version": "0.9.58"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router_info
+}
+
+func TestValidateAcceptsValidRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	assert.Nil(router_info.Validate(time.Unix(1600000000, 0).Add(time.Hour)))
+}
+
+func TestValidateReportsTamperedSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	router_info[len(router_info)-1] ^= 0xff
+	err := router_info.Validate(time.Unix(1600000000, 0))
+	if assert.NotNil(err) {
+		assert.Equal("failed to verify: invalid signature", err.Error())
+		assert.True(errors.Is(err, ErrRouterInfoSignature))
+	}
+}
+
+func TestValidateReportsStaleRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	err := router_info.Validate(time.Unix(1600000000, 0).Add(ROUTER_INFO_MAX_AGE + time.Second))
+	if assert.NotNil(err) {
+		assert.Equal("error validating router info: published too long ago", err.Error())
+		assert.True(errors.Is(err, ErrRouterInfoStale))
+	}
+}
+
+func TestValidateReportsRouterInfoFromFuture(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	err := router_info.Validate(time.Unix(1600000000, 0).Add(-ROUTER_INFO_MAX_CLOCK_SKEW - time.Second))
+	if assert.NotNil(err) {
+		assert.Equal("error validating router info: published in the future", err.Error())
+		assert.True(errors.Is(err, ErrRouterInfoFromFuture))
+	}
+}
+
+func TestValidateAllowsClockSkew(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	assert.Nil(router_info.Validate(time.Unix(1600000000, 0).Add(-ROUTER_INFO_MAX_CLOCK_SKEW)))
+}
+
+func TestValidateReportsInvalidAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	options, err := GoMapToMapping(map[string]string{"netId": "2", "router.version": "0.9.58"})
+	assert.Nil(err)
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		NewDate(time.Unix(1600000000, 0)),
+		[]RouterAddress{buildRouterAddress(NTCP2_TRANSPORT_STYLE)},
+		options,
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	err = router_info.Validate(time.Unix(1600000000, 0))
+	if assert.NotNil(err) {
+		assert.Equal("error parsing RouterAddress: NTCP2 address missing s option", err.Error())
+		assert.True(errors.Is(err, ErrInvalidRouterAddress))
+	}
+}
+
+func TestValidateReportsTruncatedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	for length := 0; length < len(router_info); length++ {
+		err := router_info[:length].Validate(time.Unix(1600000000, 0))
+		assert.True(errors.Is(err, ErrRouterInfoTooShort), "length %d: %v", length, err)
+	}
+}
+
+func TestVerifyRejectsSignatureLengthMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	truncated := router_info[:len(router_info)-signature_sizes[KEYCERT_SIGN_ED25519]+KEYCERT_SIGN_DSA_SHA1_SIG_SIZE]
+	err := truncated.Verify()
+	if assert.NotNil(err) {
+		assert.Equal("error verifying router info: signature length does not match signing key type", err.Error())
+	}
+
+	extended := append(append(RouterInfo{}, router_info...), 0x00)
+	assert.NotNil(extended.Verify(), "Verify() accepted data beyond the signature")
+}
+
+// replays a signature taken from an existing RouterInfo
+type fixedSigner []byte
+
+func (s fixedSigner) Sign(data []byte) ([]byte, error) {
+	return s, nil
+}
+
+func (s fixedSigner) SignHash(h []byte) ([]byte, error) {
+	return s, nil
+}
+
+func TestRouterInfoRoundTripPreservesIdentityPadding(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := make([]byte, ed25519.SeedSize)
+	signing_private_key := crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(seed))
+	signing_public_key, err := signing_private_key.Public()
+	assert.Nil(err)
+	signer, err := signing_private_key.NewSigner()
+	assert.Nil(err)
+	var encryption_key crypto.X25519PublicKey
+	router_identity, err := NewRouterIdentityWithPadding(
+		encryption_key,
+		signing_public_key,
+		Certificate([]byte{CERT_KEY, 0x00, 0x04, 0x00, KEYCERT_SIGN_ED25519, 0x00, KEYCERT_CRYPTO_X25519}),
+		bytes.NewReader(bytes.Repeat([]byte{0x5a, 0xa5}, KEYS_AND_CERT_DATA_SIZE)),
+	)
+	assert.Nil(err)
+	router_info, err := NewRouterInfo(router_identity, NewDate(time.Unix(1600000000, 0)), nil, buildMapping(), signer)
+	assert.Nil(err)
+	assert.Nil(router_info.Verify())
+
+	compressed, err := router_info.Compress()
+	assert.Nil(err)
+	parsed, err := ReadRouterInfoMaybeCompressed(compressed, DefaultParseLimits())
+	assert.Nil(err)
+	parsed_identity, err := parsed.RouterIdentity()
+	assert.Nil(err)
+	published, err := parsed.Published()
+	assert.Nil(err)
+	router_addresses, err := parsed.RouterAddresses()
+	assert.Nil(err)
+
+	reserialized, err := NewRouterInfo(
+		parsed_identity,
+		published,
+		router_addresses,
+		parsed.Options(),
+		fixedSigner(parsed.SignatureBytes()),
+	)
+	assert.Nil(err)
+	assert.Equal(router_info, reserialized)
+	assert.Nil(reserialized.Verify(), "signature did not verify after a round trip")
+}
+
+func TestReadRouterInfoReturnsRemainder(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	data := append(append([]byte{}, router_info...), 0x01, 0x02)
+	read, remainder, err := ReadRouterInfo(data, DefaultParseLimits())
+	assert.Nil(err)
+	assert.Equal(router_info, read)
+	assert.Equal([]byte{0x01, 0x02}, remainder)
+}
+
+func TestReadRouterInfoRejectsTooManyAddresses(t *testing.T) {
+	assert := assert.New(t)
+
+	var published Date
+	copy(published[:], buildDate())
+	router_info, err := NewRouterInfo(
+		buildRouterIdentity(),
+		published,
+		[]RouterAddress{buildRouterAddress("NTCP2"), buildRouterAddress("SSU2")},
+		buildMapping(),
+		zeroSigner{},
+	)
+	assert.Nil(err)
+	limits := DefaultParseLimits()
+	limits.MaxAddresses = 1
+	_, _, err = ReadRouterInfo(router_info, limits)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: too many router addresses", err.Error())
+	}
+}
+
+func TestReadRouterInfoRejectsLargeAddressOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	limits := DefaultParseLimits()
+	limits.MaxMappingSize = len(buildMapping()) - 3
+	_, _, err := ReadRouterInfo(buildFullRouterInfo(), limits)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router address: options mapping too large", err.Error())
+	}
+}
+
+func TestReadRouterInfoRejectsLargeOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"caps": "XR", "netId": "2", "router.version": "0.9.64"})
+	limits := DefaultParseLimits()
+	limits.MaxMappingSize = len(buildMapping()) - 2
+	_, _, err := ReadRouterInfo(router_info, limits)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: options mapping too large", err.Error())
+	}
+}
+
+func TestReadRouterInfoRejectsTooManyAddressOptionPairs(t *testing.T) {
+	assert := assert.New(t)
+
+	limits := DefaultParseLimits()
+	limits.MaxMappingPairs = 1
+	_, _, err := ReadRouterInfo(buildFullRouterInfo(), limits)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router address: options mapping has too many pairs", err.Error())
+	}
+}
+
+func TestReadRouterInfoRejectsTooManyOptionPairs(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"caps": "XR", "netId": "2", "router.version": "0.9.64"})
+	limits := DefaultParseLimits()
+	limits.MaxMappingPairs = 2
+	_, _, err := ReadRouterInfo(router_info, limits)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: options mapping has too many pairs", err.Error())
+	}
+}
+
+func TestReadRouterInfoTreatsZeroLimitsAsDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"caps": "XR", "netId": "2", "router.version": "0.9.64"})
+	read, remainder, err := ReadRouterInfo(router_info, ParseLimits{})
+	assert.Nil(err)
+	assert.Equal(router_info, read)
+	assert.Equal(0, len(remainder))
+}
+
+func TestReadRouterInfoReportsTruncation(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildFullRouterInfo()
+	_, _, err := ReadRouterInfo(router_info[:len(router_info)-1], DefaultParseLimits())
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: not enough data", err.Error())
+	}
+}
+
+func TestRouterInfoMarshalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	data, err := json.Marshal(router_info)
+	assert.Nil(err)
+	ident_hash, err := router_info.IdentHash()
+	assert.Nil(err)
+	b32 := strings.TrimRight(base32.EncodeToString(ident_hash[:]), "=")
+	assert.Contains(string(data), `"ident_hash":"`+b32+`"`)
+	assert.Contains(string(data), `"transport_style":"NTCP2"`)
+	assert.Contains(string(data), `"published":"2020-09-13T12:26:40Z"`)
+
+	var decoded map[string]interface{}
+	assert.Nil(json.Unmarshal(data, &decoded))
+	options := decoded["options"].(map[string]interface{})
+	assert.Equal("2", options["netId"])
+	addresses := decoded["addresses"].([]interface{})
+	if assert.Equal(1, len(addresses)) {
+		address_options := addresses[0].(map[string]interface{})["options"].(map[string]interface{})
+		assert.Equal("10.0.0.1", address_options["host"])
+	}
+}
+
+func TestRouterInfoMarshalJSONRejectsTruncatedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := json.Marshal(RouterInfo(make([]byte, 10)))
+	assert.NotNil(err)
+	router_info := buildFullRouterInfo()
+	for length := 0; length < len(router_info)-signature_sizes[KEYCERT_SIGN_P256]; length++ {
+		data, err := router_info[:length].MarshalJSON()
+		assert.NotNil(err, "length %d", length)
+		assert.Nil(data, "length %d", length)
+	}
+}
+
+func TestReadRouterInfoStrictReadsExactRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	read, err := ReadRouterInfoStrict(router_info)
+	assert.Nil(err)
+	assert.Equal(router_info, read)
+}
+
+func TestReadRouterInfoStrictRejectsTrailingByte(t *testing.T) {
+	assert := assert.New(t)
+
+	data := append(append([]byte{}, buildValidRouterInfo(t)...), 0x00)
+	read, err := ReadRouterInfoStrict(data)
+	assert.Nil(read)
+	if assert.NotNil(err) {
+		assert.Equal("error parsing router info: data beyond end of router info", err.Error())
+	}
+}
+
+func TestSignatureBytesAndOptionsDoNotPanicWhenTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	for n := 0; n < len(router_info); n++ {
+		truncated := router_info[:n]
+		assert.Nil(truncated.SignatureBytes(), "length %d", n)
+		_, err := truncated.CheckedOptions()
+		if n < len(router_info)-64 {
+			assert.NotNil(err, "length %d", n)
+		}
+		truncated.Options()
+	}
+}
+
+func TestCheckedOptionsReturnsOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	options, err := buildFullRouterInfo().CheckedOptions()
+	assert.Nil(err)
+	assert.Equal(buildMapping(), options)
+}
+
+func TestVerifyReportsTruncatedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	for n := 0; n < len(router_info); n++ {
+		assert.NotNil(RouterInfo(router_info[:n]).Verify(), "length %d", n)
+	}
+}
+
+func TestVerifyReportsCorruptedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildValidRouterInfo(t)
+	for i := len(buildRouterIdentity()); i < len(router_info); i++ {
+		corrupted := append(RouterInfo{}, router_info...)
+		corrupted[i] ^= 0xff
+		assert.NotNil(corrupted.Verify(), "corrupted byte %d", i)
+	}
+}
+
+func TestValidateRequiredOptionsReportsTruncatedRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := buildRouterInfoWithOptions(map[string]string{"netId": "2", "router.version": "0.9.24"})
+	for _, n := range []int{0, 2, len(buildRouterIdentity()) + 8, len(router_info) - signature_sizes[KEYCERT_SIGN_P256] - 1} {
+		err := RouterInfo(router_info[:n]).ValidateRequiredOptions()
+		assert.NotNil(err, "length %d", n)
+	}
+	err := RouterInfo{}.ValidateRequiredOptions()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing KeysAndCert: no data", err.Error())
+	}
+}
@@ -1,3 +1,76 @@
 package common
 
+import (
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
 type Signature []byte
+
+//
+// Verify sig over data with the SigningPublicKey key_bytes of the given Signing Key Type,
+// for signatures that are not part of a structure.  Returns nil if the signature is valid
+// and an error if it is not or the key cannot be used.
+//
+func VerifyDetached(signing_key_type int, key_bytes, data, sig []byte) (err error) {
+	signing_public_key, err := newSigningPublicKey(signing_key_type, key_bytes)
+	if err != nil {
+		return
+	}
+	verifier, err := signing_public_key.NewVerifier()
+	if err != nil {
+		return
+	}
+	err = verifier.Verify(data, sig)
+	return
+}
+
+//
+// Build the SigningPublicKey of the given Signing Key Type from exactly its key bytes,
+// by laying them out as in a KeysAndCert and constructing the key through a Key
+// Certificate of that type.
+//
+func newSigningPublicKey(signing_key_type int, key_bytes []byte) (signing_public_key crypto.SigningPublicKey, err error) {
+	key_size, known := signing_public_key_sizes[signing_key_type]
+	if !known {
+		log.WithFields(log.Fields{
+			"at":               "VerifyDetached",
+			"signing_key_type": signing_key_type,
+			"reason":           "unknown signing key type",
+		}).Error("error constructing signing public key")
+		err = errors.New("error constructing signing public key: unknown signing key type")
+		return
+	}
+	if len(key_bytes) != key_size {
+		log.WithFields(log.Fields{
+			"at":               "VerifyDetached",
+			"signing_key_type": signing_key_type,
+			"key_len":          len(key_bytes),
+			"required_len":     key_size,
+			"reason":           "wrong key length",
+		}).Error("error constructing signing public key")
+		err = errors.New("error constructing signing public key: wrong key length")
+		return
+	}
+	// keys that fit the signing key field are right aligned in it, larger keys fill it
+	// and store the rest in the Key Certificate after the two key types
+	key_certificate := newKeyCertificate(signing_key_type, KEYCERT_CRYPTO_ELG)
+	signing_key_field := make([]byte, KEYCERT_SPK_SIZE)
+	if key_size > KEYCERT_SPK_SIZE {
+		copy(signing_key_field, key_bytes[:KEYCERT_SPK_SIZE])
+		copy(key_certificate[CERT_MIN_SIZE+4:], key_bytes[KEYCERT_SPK_SIZE:])
+	} else {
+		copy(signing_key_field[KEYCERT_SPK_SIZE-key_size:], key_bytes)
+	}
+	signing_public_key, err = key_certificate.ConstructSigningPublicKey(signing_key_field)
+	if err == nil && signing_public_key == nil {
+		log.WithFields(log.Fields{
+			"at":               "VerifyDetached",
+			"signing_key_type": signing_key_type,
+			"reason":           "unsupported signing key type",
+		}).Error("error constructing signing public key")
+		err = errors.New("error constructing signing public key: unsupported signing key type")
+	}
+	return
+}
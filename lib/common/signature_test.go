@@ -0,0 +1,84 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVerifyDetachedEd25519(t *testing.T) {
+	assert := assert.New(t)
+
+	private_key := crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	public_key, err := private_key.Public()
+	assert.Nil(err)
+	signer, err := private_key.NewSigner()
+	assert.Nil(err)
+	data := []byte("detached data")
+	sig, err := signer.Sign(data)
+	assert.Nil(err)
+
+	assert.Nil(VerifyDetached(KEYCERT_SIGN_ED25519, public_key, data, sig))
+	assert.NotNil(VerifyDetached(KEYCERT_SIGN_ED25519, public_key, []byte("other data"), sig))
+}
+
+func TestVerifyDetachedDSA(t *testing.T) {
+	assert := assert.New(t)
+
+	var private_key crypto.DSAPrivateKey
+	private_key, err := private_key.Generate()
+	assert.Nil(err)
+	public_key, err := private_key.Public()
+	assert.Nil(err)
+	signer, err := private_key.NewSigner()
+	assert.Nil(err)
+	data := []byte("detached data")
+	sig, err := signer.Sign(data)
+	assert.Nil(err)
+
+	assert.Nil(VerifyDetached(KEYCERT_SIGN_DSA_SHA1, public_key[:], data, sig))
+	assert.Equal(crypto.ErrInvalidSignature, VerifyDetached(KEYCERT_SIGN_DSA_SHA1, public_key[:], []byte("other data"), sig))
+}
+
+func TestVerifyDetachedRejectsWrongKeyLength(t *testing.T) {
+	assert := assert.New(t)
+
+	err := VerifyDetached(KEYCERT_SIGN_ED25519, make([]byte, 31), []byte{}, make([]byte, 64))
+	if assert.NotNil(err) {
+		assert.Equal("error constructing signing public key: wrong key length", err.Error())
+	}
+}
+
+func TestVerifyDetachedRejectsUnknownKeyType(t *testing.T) {
+	assert := assert.New(t)
+
+	err := VerifyDetached(0xff, make([]byte, 32), []byte{}, make([]byte, 64))
+	if assert.NotNil(err) {
+		assert.Equal("error constructing signing public key: unknown signing key type", err.Error())
+	}
+}
+
+func TestVerifyDetachedRejectsUnsupportedKeyType(t *testing.T) {
+	assert := assert.New(t)
+
+	err := VerifyDetached(KEYCERT_SIGN_RSA2048, make([]byte, KEYCERT_SIGN_RSA2048_SIZE), []byte{}, make([]byte, 256))
+	if assert.NotNil(err) {
+		assert.Equal("error constructing signing public key: unsupported signing key type", err.Error())
+	}
+}
+
+func TestNewSigningPublicKeyKeepsKeyBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, signing_key_type := range []int{KEYCERT_SIGN_DSA_SHA1, KEYCERT_SIGN_P256, KEYCERT_SIGN_P384, KEYCERT_SIGN_P521, KEYCERT_SIGN_ED25519} {
+		key_bytes := make([]byte, signing_public_key_sizes[signing_key_type])
+		for i := range key_bytes {
+			key_bytes[i] = byte(i + 1)
+		}
+		signing_public_key, err := newSigningPublicKey(signing_key_type, key_bytes)
+		if assert.Nil(err, "signing key type %d", signing_key_type) {
+			assert.Equal(key_bytes, signing_public_key.Bytes(), "signing key type %d", signing_key_type)
+		}
+	}
+}
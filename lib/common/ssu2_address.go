@@ -0,0 +1,132 @@
+package common
+
+/*
+I2P SSU2 RouterAddress
+https://geti2p.net/spec/ssu2#published-router-info
+Accurate for version 0.9.56
+
+A RouterAddress with a transport_style of "SSU2" carries the following
+options in addition to the common host and port:
+
+s :: Static X25519 public key, I2P base64 encoded
+     length -> 32 bytes decoded
+
+i :: Intro key, I2P base64 encoded
+     length -> 32 bytes decoded
+
+v :: Protocol version, currently "2"
+
+ihN, itagN, iexpN :: Introducer router hash (base64), relay tag and expiration
+                     (seconds since epoch) for introducer N, 0 <= N <= 2, read
+                     with RouterAddress.Introducers
+*/
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	log "github.com/sirupsen/logrus"
+)
+
+// Transport style and limits for SSU2 RouterAddresses
+const (
+	SSU2_TRANSPORT_STYLE = "SSU2"
+	SSU2_KEY_SIZE        = 32
+)
+
+// Option keys specific to SSU2 RouterAddresses
+const (
+	SSU2_OPTION_INTRO_KEY = "i"
+)
+
+//
+// A SSU2Address is a RouterAddress with a transport style of SSU2, with
+// accessors for the SSU2 specific options.
+//
+type SSU2Address []byte
+
+//
+// Check that a RouterAddress uses the SSU2 transport style and return it as a SSU2Address.
+//
+func NewSSU2Address(router_address RouterAddress) (ssu2_address SSU2Address, err error) {
+	style, err := router_address.TransportStyle()
+	if err != nil {
+		return
+	}
+	style_str, _ := style.Data()
+	if style_str != SSU2_TRANSPORT_STYLE {
+		log.WithFields(log.Fields{
+			"at":              "NewSSU2Address",
+			"transport_style": style_str,
+			"reason":          "transport style is not SSU2",
+		}).Error("invalid ssu2 address")
+		err = errors.New("error parsing SSU2 address: transport style is not SSU2")
+		return
+	}
+	ssu2_address = SSU2Address(router_address)
+	return
+}
+
+//
+// Return the decoded X25519 static key from the "s" option.
+//
+func (ssu2_address SSU2Address) StaticKey() (key [SSU2_KEY_SIZE]byte, err error) {
+	return ssu2_address.decodeKey(ROUTER_ADDRESS_OPTION_STATIC_KEY)
+}
+
+//
+// Return the decoded intro key from the "i" option.
+//
+func (ssu2_address SSU2Address) IntroKey() (key [SSU2_KEY_SIZE]byte, err error) {
+	return ssu2_address.decodeKey(SSU2_OPTION_INTRO_KEY)
+}
+
+//
+// Return the protocol version from the "v" option.
+//
+func (ssu2_address SSU2Address) Version() (version string, err error) {
+	return ssu2_address.requireOption(ROUTER_ADDRESS_OPTION_VERSION)
+}
+
+//
+// Look up an option that must be present in a SSU2Address.
+//
+func (ssu2_address SSU2Address) requireOption(key string) (value string, err error) {
+	options, err := RouterAddress(ssu2_address).Options()
+	if err != nil {
+		return
+	}
+	value, present := options.Get(key)
+	if !present {
+		log.WithFields(log.Fields{
+			"at":     "(SSU2Address) requireOption",
+			"option": key,
+			"reason": "option missing",
+		}).Error("invalid ssu2 address")
+		err = fmt.Errorf("error parsing SSU2 address: missing %s option", key)
+	}
+	return
+}
+
+//
+// Decode a base64 key stored in a SSU2Address option.
+//
+func (ssu2_address SSU2Address) decodeKey(key string) (decoded [SSU2_KEY_SIZE]byte, err error) {
+	value, err := ssu2_address.requireOption(key)
+	if err != nil {
+		return
+	}
+	key_bytes, err := base64.DecodeFromString(value)
+	if err != nil || len(key_bytes) != SSU2_KEY_SIZE {
+		log.WithFields(log.Fields{
+			"at":       "(SSU2Address) decodeKey",
+			"option":   key,
+			"data_len": len(key_bytes),
+			"reason":   "invalid key encoding",
+		}).Error("invalid ssu2 address")
+		err = fmt.Errorf("error parsing SSU2 address: invalid %s option", key)
+		return
+	}
+	copy(decoded[:], key_bytes)
+	return
+}
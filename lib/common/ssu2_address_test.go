@@ -0,0 +1,119 @@
+package common
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildKeyString(b byte) string {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = b
+	}
+	return base64.EncodeToString(key)
+}
+
+func TestNewSSU2AddressRejectsOtherTransports(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewSSU2Address(buildRouterAddress("NTCP2"))
+	if assert.NotNil(err) {
+		assert.Equal("error parsing SSU2 address: transport style is not SSU2", err.Error())
+	}
+}
+
+func TestSSU2AddressReadsKeysAndVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	ssu2_address, err := NewSSU2Address(buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"host": "127.0.0.1",
+		"port": "4567",
+		"s":    buildKeyString(0x01),
+		"i":    buildKeyString(0x02),
+		"v":    "2",
+	}))
+	assert.Nil(err)
+
+	static_key, err := ssu2_address.StaticKey()
+	assert.Nil(err)
+	assert.Equal(byte(0x01), static_key[0])
+	intro_key, err := ssu2_address.IntroKey()
+	assert.Nil(err)
+	assert.Equal(byte(0x02), intro_key[31])
+	version, err := ssu2_address.Version()
+	assert.Nil(err)
+	assert.Equal("2", version)
+}
+
+func TestSSU2AddressReportsMissingStaticKey(t *testing.T) {
+	assert := assert.New(t)
+
+	ssu2_address, _ := NewSSU2Address(buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"i": buildKeyString(0x02),
+		"v": "2",
+	}))
+	_, err := ssu2_address.StaticKey()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing SSU2 address: missing s option", err.Error())
+	}
+}
+
+func TestSSU2AddressReportsMalformedIntroKey(t *testing.T) {
+	assert := assert.New(t)
+
+	ssu2_address, _ := NewSSU2Address(buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"i": "AAAA",
+	}))
+	_, err := ssu2_address.IntroKey()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing SSU2 address: invalid i option", err.Error())
+	}
+}
+
+func TestSSU2AddressReadsIntroducers(t *testing.T) {
+	assert := assert.New(t)
+
+	ssu2_address, _ := NewSSU2Address(buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"ih0":   buildKeyString(0x03),
+		"itag0": "1234",
+		"iexp0": "1700000000",
+		"ih1":   buildKeyString(0x04),
+		"itag1": "5678",
+	}))
+	introducers, err := RouterAddress(ssu2_address).Introducers()
+	assert.Nil(err)
+	if assert.Equal(2, len(introducers)) {
+		assert.Equal(byte(0x03), introducers[0].Hash[0])
+		assert.Equal(uint32(1234), introducers[0].Tag)
+		assert.Equal(int64(1700000000), introducers[0].Expiration.Unix())
+		assert.Equal(byte(0x04), introducers[1].Hash[0])
+		assert.Equal(uint32(5678), introducers[1].Tag)
+		assert.True(introducers[1].Expiration.IsZero())
+	}
+}
+
+func TestSSU2AddressReportsIntroducerMissingTag(t *testing.T) {
+	assert := assert.New(t)
+
+	ssu2_address, _ := NewSSU2Address(buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"ih0": buildKeyString(0x03),
+	}))
+	_, err := RouterAddress(ssu2_address).Introducers()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing introducers: missing itag0 option", err.Error())
+	}
+}
+
+func TestSSU2AddressReportsInvalidIntroducerHash(t *testing.T) {
+	assert := assert.New(t)
+
+	ssu2_address, _ := NewSSU2Address(buildRouterAddressWithOptions(SSU2_TRANSPORT_STYLE, map[string]string{
+		"ih0":   "AAAA",
+		"itag0": "1234",
+	}))
+	_, err := RouterAddress(ssu2_address).Introducers()
+	if assert.NotNil(err) {
+		assert.Equal("error parsing introducers: invalid ih0 option", err.Error())
+	}
+}
@@ -31,7 +31,10 @@ func (str String) Length() (length int, err error) {
 		err = errors.New("error parsing string: zero length")
 		return
 	}
-	length = Integer([]byte{byte(str[0])})
+	length, _, err = NewInteger([]byte(str[:1]), 1)
+	if err != nil {
+		return
+	}
 	inferred_len := length + 1
 	str_len := len(str)
 	if inferred_len > str_len {
@@ -113,3 +116,22 @@ func ReadString(data []byte) (str String, remainder []byte, err error) {
 	}
 	return
 }
+
+//
+// Read consecutive strings from a slice of bytes until it is exhausted.  If a string is
+// truncated, the strings read before it are returned along with the unparsed data from
+// the truncated string onward and the error encountered parsing it.
+//
+func ReadStringList(data []byte) (strs []String, remainder []byte, err error) {
+	remainder = data
+	for len(remainder) > 0 {
+		var str String
+		str, remainder, err = ReadString(remainder)
+		if err != nil {
+			remainder = data[len(data)-len(str):]
+			return
+		}
+		strs = append(strs, str)
+	}
+	return
+}
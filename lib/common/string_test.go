@@ -146,3 +146,28 @@ func TestReadStringErrWhenDataTooShort(t *testing.T) {
 	assert.Equal(1, int(str[1]), "ReadString() did not return the correct partial string")
 	assert.Equal(len(remainder), 0, "ReadString() returned a remainder when the string data was too short")
 }
+
+func TestReadStringListReadsConsecutiveStrings(t *testing.T) {
+	assert := assert.New(t)
+
+	strs, remainder, err := ReadStringList([]byte{0x02, 0x30, 0x39, 0x04, 0x30, 0x2e, 0x39, 0x35})
+	assert.Nil(err)
+	assert.Equal(0, len(remainder))
+	if assert.Equal(2, len(strs)) {
+		first, _ := strs[0].Data()
+		assert.Equal("09", first)
+		second, _ := strs[1].Data()
+		assert.Equal("0.95", second)
+	}
+}
+
+func TestReadStringListReturnsTruncatedRemainder(t *testing.T) {
+	assert := assert.New(t)
+
+	strs, remainder, err := ReadStringList([]byte{0x01, 0x41, 0x03, 0x42})
+	if assert.NotNil(err) {
+		assert.Equal("string parsing warning: string data is shorter than specified by length", err.Error())
+	}
+	assert.Equal(1, len(strs))
+	assert.Equal([]byte{0x03, 0x42}, remainder)
+}
@@ -0,0 +1,91 @@
+//
+// helpers for building common structures in tests
+//
+package testutil
+
+import (
+	"crypto/ed25519"
+	"github.com/go-i2p/go-i2p/lib/common"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"testing"
+	"time"
+)
+
+//
+// Describes how a RouterInfo from BuildRouterInfo differs from the one returned by
+// MinimalRouterInfo.  The zero value changes nothing.
+//
+type RouterInfoConfig struct {
+	// first byte of the Ed25519 seed, RouterInfos built with different seeds have
+	// different IdentHashes
+	Seed byte
+	// options set on top of the defaults, an option set to "" is left out
+	Options map[string]string
+	// addresses published instead of the single NTCP2 address if not nil
+	Addresses []common.RouterAddress
+}
+
+//
+// Return a signed RouterInfo published now with an Ed25519 signing key, an X25519
+// encryption key and a single NTCP2 address on 127.0.0.1, which passes Validate.
+// The keys are fixed, so every RouterInfo returned has the same IdentHash.
+//
+func MinimalRouterInfo(t testing.TB) common.RouterInfo {
+	t.Helper()
+	return BuildRouterInfo(t, RouterInfoConfig{})
+}
+
+//
+// Return a signed RouterInfo like MinimalRouterInfo, changed as described by config.
+//
+func BuildRouterInfo(t testing.TB, config RouterInfoConfig) common.RouterInfo {
+	t.Helper()
+	var encryption_key crypto.X25519PublicKey
+	for i := range encryption_key {
+		encryption_key[i] = 0x01
+	}
+	address, err := common.NewRouterAddressFromComponents(
+		10,
+		common.Date{},
+		common.NTCP2_TRANSPORT_STYLE,
+		map[string]string{
+			common.ROUTER_ADDRESS_OPTION_HOST:       "127.0.0.1",
+			common.ROUTER_ADDRESS_OPTION_PORT:       "12345",
+			common.ROUTER_ADDRESS_OPTION_STATIC_KEY: base64.EncodeToString(encryption_key[:]),
+			common.NTCP2_OPTION_IV:                  base64.EncodeToString(make([]byte, 16)),
+			common.ROUTER_ADDRESS_OPTION_VERSION:    "2",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := []common.RouterAddress{address}
+	if config.Addresses != nil {
+		addresses = config.Addresses
+	}
+	options := map[string]string{
+		common.ROUTER_INFO_OPTION_NET_ID:         "2",
+		common.ROUTER_INFO_OPTION_ROUTER_VERSION: "0.9.58",
+	}
+	for key, value := range config.Options {
+		if value == "" {
+			delete(options, key)
+		} else {
+			options[key] = value
+		}
+	}
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = config.Seed
+	router_info, err := common.NewRouterInfoDeterministic(
+		encryption_key,
+		crypto.Ed25519PrivateKey(ed25519.NewKeyFromSeed(seed)),
+		time.Now(),
+		addresses,
+		options,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router_info
+}
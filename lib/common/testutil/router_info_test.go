@@ -0,0 +1,60 @@
+package testutil
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMinimalRouterInfoVerifies(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := MinimalRouterInfo(t)
+	assert.Nil(router_info.Verify())
+	assert.Nil(router_info.Validate(time.Now()))
+	addresses, err := router_info.RouterAddresses()
+	assert.Nil(err)
+	if assert.Equal(1, len(addresses)) {
+		assert.Nil(addresses[0].ValidateForTransport())
+	}
+}
+
+func TestBuildRouterInfoOverridesOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	router_info := BuildRouterInfo(t, RouterInfoConfig{
+		Options: map[string]string{"netId": "3", "router.version": ""},
+	})
+	assert.Nil(router_info.Verify())
+	options, err := router_info.CheckedOptions()
+	assert.Nil(err)
+	net_id, present := options.Get("netId")
+	assert.True(present)
+	assert.Equal("3", net_id)
+	_, present = options.Get("router.version")
+	assert.False(present, "option set to the empty string was not left out")
+}
+
+func TestBuildRouterInfoSeedChangesIdentHash(t *testing.T) {
+	assert := assert.New(t)
+
+	first, err := BuildRouterInfo(t, RouterInfoConfig{Seed: 1}).IdentHash()
+	assert.Nil(err)
+	second, err := BuildRouterInfo(t, RouterInfoConfig{Seed: 2}).IdentHash()
+	assert.Nil(err)
+	minimal, err := MinimalRouterInfo(t).IdentHash()
+	assert.Nil(err)
+	assert.NotEqual(first, second)
+	assert.NotEqual(minimal, first)
+}
+
+func TestBuildRouterInfoReplacesAddresses(t *testing.T) {
+	assert := assert.New(t)
+
+	address, err := common.NewRouterAddressFromComponents(0, common.Date{}, "SSU2", nil)
+	assert.Nil(err)
+	router_info := BuildRouterInfo(t, RouterInfoConfig{Addresses: []common.RouterAddress{address}})
+	assert.Nil(router_info.Verify())
+	assert.Equal([]string{"SSU2"}, router_info.TransportStyles())
+}
@@ -368,9 +368,8 @@ func getContentLength(data []byte) (int, error) {
 		content_length_bytes[:],
 		data[SU3_MAGIC_BYTE_LEN+1+1+SU3_SIGNATURE_TYPE_LEN+SU3_SIGNATURE_LENGTH_LEN+1+1+1+1:SU3_MAGIC_BYTE_LEN+1+1+SU3_SIGNATURE_TYPE_LEN+SU3_SIGNATURE_LENGTH_LEN+1+1+1+1+SU3_CONTENT_LENGTH_LEN],
 	)
-	content_length = common.Integer(content_length_bytes[:])
-
-	return content_length, nil
+	// the 8 byte length may not fit in an int
+	return common.NonNegativeInteger(content_length_bytes[:])
 }
 
 func checkByte24Unused(data []byte) error {
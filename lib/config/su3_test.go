@@ -199,6 +199,10 @@ func TestGetContentLength(t *testing.T) {
 	content_length, err = getContentLength(append(su3_base, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}...))
 	assert.Equal(ERR_NOT_ENOUGH_SU3_DATA, err)
 	assert.Equal(0, content_length)
+
+	content_length, err = getContentLength(append(su3_base, []byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}...))
+	assert.NotNil(err, "content length overflowing int was not rejected")
+	assert.Equal(0, content_length)
 }
 
 func TestCheckByte24Unused(t *testing.T) {
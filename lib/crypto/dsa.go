@@ -113,10 +113,23 @@ func (v *DSAVerifier) VerifyHash(h, sig []byte) (err error) {
 	return
 }
 
+// verify a signature over data with a dsa public key without keeping a verifier around
+func VerifyDSA(pubKey [128]byte, data, sig []byte) (err error) {
+	v, err := DSAPublicKey(pubKey).NewVerifier()
+	if err == nil {
+		err = v.Verify(data, sig)
+	}
+	return
+}
+
 func (k DSAPublicKey) Len() int {
 	return len(k)
 }
 
+func (k DSAPublicKey) Bytes() []byte {
+	return k[:]
+}
+
 type DSASigner struct {
 	k *dsa.PrivateKey
 }
@@ -136,7 +149,9 @@ func (k DSAPrivateKey) Public() (pk DSAPublicKey, err error) {
 	if p == nil {
 		err = ErrInvalidKeyFormat
 	} else {
-		copy(pk[:], p.Y.Bytes())
+		// right align Y, it is a big-endian integer that may be shorter than the key
+		yb := p.Y.Bytes()
+		copy(pk[len(pk)-len(yb):], yb)
 	}
 	return
 }
@@ -145,12 +160,19 @@ func (k DSAPrivateKey) Generate() (s DSAPrivateKey, err error) {
 	dk := new(dsa.PrivateKey)
 	err = generateDSA(dk, rand.Reader)
 	if err == nil {
-		copy(k[:], dk.X.Bytes())
-		s = k
+		s = dsaPrivateKeyFromX(dk.X)
 	}
 	return
 }
 
+// encode a dsa private exponent as a key
+// right align X, it is a big-endian integer that may be shorter than the key
+func dsaPrivateKeyFromX(X *big.Int) (k DSAPrivateKey) {
+	xb := X.Bytes()
+	copy(k[len(k)-len(xb):], xb)
+	return
+}
+
 func (ds *DSASigner) Sign(data []byte) (sig []byte, err error) {
 	h := sha1.Sum(data)
 	sig, err = ds.SignHash(h[:])
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	log "github.com/sirupsen/logrus"
 	"io"
+	"math/big"
 	"testing"
 )
 
@@ -87,3 +88,126 @@ func BenchmarkDSASignVerify(b *testing.B) {
 	}
 	log.Infof("%d fails %d signs", fail, b.N)
 }
+
+// unlike ecdsa, negating s does not yield another valid dsa signature, so
+// there is no high-s form for a verifier to reject or a signer to canonicalize
+func TestDSANegatedSIsInvalid(t *testing.T) {
+	var sk DSAPrivateKey
+	sk, err := sk.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, _ := sk.NewSigner()
+	verify, _ := pk.NewVerifier()
+	data := make([]byte, 512)
+	io.ReadFull(rand.Reader, data)
+	for i := 0; i < 8; i++ {
+		sig, err := signer.Sign(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = verify.Verify(data, sig); err != nil {
+			t.Fatalf("failed to verify signature: %s", err)
+		}
+		s := new(big.Int).SetBytes(sig[20:])
+		negated := make([]byte, 40)
+		copy(negated, sig[:20])
+		nb := new(big.Int).Sub(dsaq, s).Bytes()
+		copy(negated[40-len(nb):], nb)
+		if err = verify.Verify(data, negated); err != ErrInvalidSignature {
+			t.Logf("signature with negated s was accepted")
+			t.Fail()
+		}
+	}
+}
+
+func TestVerifyDSA(t *testing.T) {
+	var sk DSAPrivateKey
+	sk, err := sk.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, _ := sk.NewSigner()
+	data := make([]byte, 512)
+	io.ReadFull(rand.Reader, data)
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyDSA(pk, data, sig); err != nil {
+		t.Fatalf("failed to verify signature: %s", err)
+	}
+	data[0] ^= 0xff
+	if err = VerifyDSA(pk, data, sig); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+	if err = VerifyDSA(pk, data, sig[:39]); err != ErrBadSignatureSize {
+		t.Fatalf("expected ErrBadSignatureSize, got %v", err)
+	}
+}
+
+func TestDSAPrivateKeyWithShortX(t *testing.T) {
+	// an exponent with a leading zero byte must keep its value once encoded
+	x := new(big.Int).SetBytes([]byte{
+		0x42, 0x13, 0x37, 0x42, 0x13, 0x37, 0x42, 0x13, 0x37, 0x42,
+		0x13, 0x37, 0x42, 0x13, 0x37, 0x42, 0x13, 0x37, 0x42,
+	})
+	sk := dsaPrivateKeyFromX(x)
+	if sk[0] != 0 || new(big.Int).SetBytes(sk[:]).Cmp(x) != 0 {
+		t.Fatalf("short exponent was not right aligned: %x", sk)
+	}
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := new(big.Int).Exp(dsag, x, dsap)
+	if new(big.Int).SetBytes(pk[:]).Cmp(y) != 0 {
+		t.Fatal("public key does not match the exponent")
+	}
+	signer, _ := sk.NewSigner()
+	data := []byte("short exponent")
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyDSA(pk, data, sig); err != nil {
+		t.Fatalf("failed to verify signature: %s", err)
+	}
+}
+
+func TestDSAPublicKeyWithShortY(t *testing.T) {
+	// find the first exponent whose public value has a leading zero byte
+	var sk DSAPrivateKey
+	var y *big.Int
+	for x := int64(1); ; x++ {
+		y = new(big.Int).Exp(dsag, big.NewInt(x), dsap)
+		if len(y.Bytes()) < len(DSAPublicKey{}) {
+			sk = dsaPrivateKeyFromX(big.NewInt(x))
+			break
+		}
+	}
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk[0] != 0 || new(big.Int).SetBytes(pk[:]).Cmp(y) != 0 {
+		t.Fatalf("short public value was not right aligned: %x", pk)
+	}
+	signer, _ := sk.NewSigner()
+	data := []byte("short public value")
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyDSA(pk, data, sig); err != nil {
+		t.Fatalf("failed to verify signature: %s", err)
+	}
+}
@@ -51,6 +51,10 @@ func (k ECP256PublicKey) Len() int {
 	return len(k)
 }
 
+func (k ECP256PublicKey) Bytes() []byte {
+	return k[:]
+}
+
 func (k ECP256PublicKey) NewVerifier() (Verifier, error) {
 	return createECVerifier(elliptic.P256(), crypto.SHA256, k[:])
 }
@@ -62,6 +66,10 @@ func (k ECP384PublicKey) Len() int {
 	return len(k)
 }
 
+func (k ECP384PublicKey) Bytes() []byte {
+	return k[:]
+}
+
 func (k ECP384PublicKey) NewVerifier() (Verifier, error) {
 	return createECVerifier(elliptic.P384(), crypto.SHA384, k[:])
 }
@@ -73,6 +81,10 @@ func (k ECP521PublicKey) Len() int {
 	return len(k)
 }
 
+func (k ECP521PublicKey) Bytes() []byte {
+	return k[:]
+}
+
 func (k ECP521PublicKey) NewVerifier() (Verifier, error) {
 	return createECVerifier(elliptic.P521(), crypto.SHA512, k[:])
 }
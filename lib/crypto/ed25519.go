@@ -4,6 +4,18 @@ import (
 	"crypto/ed25519"
 	"crypto/sha512"
 	"errors"
+	"math/big"
+)
+
+var (
+	// field prime 2^255 - 19
+	ed25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// curve constant d = -121665/121666
+	ed25519D = func() *big.Int {
+		d := new(big.Int).ModInverse(big.NewInt(121666), ed25519P)
+		d.Mul(d, big.NewInt(-121665))
+		return d.Mod(d, ed25519P)
+	}()
 )
 
 type Ed25519PublicKey []byte
@@ -12,13 +24,57 @@ type Ed25519Verifier struct {
 	k []byte
 }
 
+func (k Ed25519PublicKey) Len() int {
+	return len(k)
+}
+
+func (k Ed25519PublicKey) Bytes() []byte {
+	return k
+}
+
 func (k Ed25519PublicKey) NewVerifier() (v Verifier, err error) {
+	if !k.canonical() {
+		err = ErrInvalidKeyFormat
+		return
+	}
 	temp := new(Ed25519Verifier)
 	temp.k = k
 	v = temp
 	return temp, nil
 }
 
+// check that the key is a canonical encoding of a point on the curve
+func (k Ed25519PublicKey) canonical() bool {
+	if len(k) != ed25519.PublicKeySize {
+		return false
+	}
+	// the encoding is y in little endian with the sign of x in the top bit
+	le := make([]byte, ed25519.PublicKeySize)
+	for i := range le {
+		le[i] = k[len(k)-1-i]
+	}
+	sign := le[0] >> 7
+	le[0] &= 0x7f
+	y := new(big.Int).SetBytes(le)
+	if y.Cmp(ed25519P) >= 0 {
+		return false
+	}
+	// x^2 = (y^2 - 1) / (d*y^2 + 1) must have a square root
+	y2 := new(big.Int).Mul(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	u.Mod(u, ed25519P)
+	w := new(big.Int).Mul(ed25519D, y2)
+	w.Add(w, big.NewInt(1))
+	w.ModInverse(w.Mod(w, ed25519P), ed25519P)
+	x2 := u.Mul(u, w)
+	x2.Mod(x2, ed25519P)
+	if x2.Sign() == 0 {
+		// x = 0 has no negative encoding
+		return sign == 0
+	}
+	return big.Jacobi(x2, ed25519P) == 1
+}
+
 func (v *Ed25519Verifier) VerifyHash(h, sig []byte) (err error) {
 	if len(sig) != ed25519.SignatureSize {
 		err = ErrBadSignatureSize
@@ -48,6 +104,28 @@ type Ed25519Signer struct {
 	k []byte
 }
 
+func (k Ed25519PrivateKey) Len() int {
+	return len(k)
+}
+
+func (k Ed25519PrivateKey) NewSigner() (s Signer, err error) {
+	if len(k) != ed25519.PrivateKeySize {
+		err = ErrInvalidKeyFormat
+		return
+	}
+	s = &Ed25519Signer{k: k}
+	return
+}
+
+func (k Ed25519PrivateKey) Public() (pk Ed25519PublicKey, err error) {
+	if len(k) != ed25519.PrivateKeySize {
+		err = ErrInvalidKeyFormat
+		return
+	}
+	pk = Ed25519PublicKey(ed25519.PrivateKey(k).Public().(ed25519.PublicKey))
+	return
+}
+
 func (s *Ed25519Signer) Sign(data []byte) (sig []byte, err error) {
 	if len(s.k) != ed25519.PrivateKeySize {
 		err = errors.New("failed to sign: invalid ed25519 private key size")
@@ -40,3 +40,44 @@ func TestEd25519(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestEd25519RejectsNonCanonicalKey(t *testing.T) {
+	// y = p encodes the same point as y = 0 but is not canonical
+	nonCanonical := Ed25519PublicKey{
+		0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	}
+	if _, err := nonCanonical.NewVerifier(); err != ErrInvalidKeyFormat {
+		t.Logf("expected ErrInvalidKeyFormat for non-canonical key, got %v", err)
+		t.Fail()
+	}
+
+	// y = 1 with the sign bit set encodes a negative zero x
+	negativeZero := make(Ed25519PublicKey, ed25519.PublicKeySize)
+	negativeZero[0] = 0x01
+	negativeZero[31] = 0x80
+	if _, err := negativeZero.NewVerifier(); err != ErrInvalidKeyFormat {
+		t.Logf("expected ErrInvalidKeyFormat for negative zero key, got %v", err)
+		t.Fail()
+	}
+
+	if _, err := Ed25519PublicKey(make([]byte, 31)).NewVerifier(); err != ErrInvalidKeyFormat {
+		t.Logf("expected ErrInvalidKeyFormat for short key, got %v", err)
+		t.Fail()
+	}
+}
+
+func TestEd25519AcceptsGeneratedKeys(t *testing.T) {
+	for i := 0; i < 32; i++ {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Ed25519PublicKey(pub).NewVerifier(); err != nil {
+			t.Logf("generated key rejected: %s", err)
+			t.Fail()
+		}
+	}
+}
@@ -61,6 +61,14 @@ func (elg *elgDecrypter) Decrypt(data []byte) (dec []byte, err error) {
 
 // decrypt an elgamal encrypted message, i2p style
 func elgamalDecrypt(priv *elgamal.PrivateKey, data []byte, zeroPadding bool) (decrypted []byte, err error) {
+	size := 512
+	if zeroPadding {
+		size += 2
+	}
+	if len(data) != size {
+		err = ElgDecryptFail
+		return
+	}
 	a := new(big.Int)
 	b := new(big.Int)
 	idx := 0
@@ -76,6 +84,11 @@ func elgamalDecrypt(priv *elgamal.PrivateKey, data []byte, zeroPadding bool) (de
 	// decrypt
 	m := new(big.Int).Mod(new(big.Int).Mul(b, new(big.Int).Exp(a, new(big.Int).Sub(new(big.Int).Sub(priv.P, priv.X), one), priv.P)), priv.P).Bytes()
 
+	// a well formed block is the 0xFF marker, digest and payload
+	if len(m) != 255 {
+		err = ElgDecryptFail
+		return
+	}
 	// check digest
 	d := sha256.Sum256(m[33:255])
 	good := 0
@@ -119,15 +132,17 @@ func (elg *ElgamalEncryption) EncryptPadding(data []byte, zeroPadding bool) (enc
 	m := new(big.Int).SetBytes(mbytes)
 	// do encryption
 	b := new(big.Int).Mod(new(big.Int).Mul(elg.b1, m), elg.p).Bytes()
+	a := elg.a.Bytes()
 
+	// right align a and b, they are big-endian integers that may be shorter than their fields
 	if zeroPadding {
 		encrypted = make([]byte, 514)
-		copy(encrypted[1:], elg.a.Bytes())
-		copy(encrypted[258:], b)
+		copy(encrypted[257-len(a):257], a)
+		copy(encrypted[514-len(b):], b)
 	} else {
 		encrypted = make([]byte, 512)
-		copy(encrypted, elg.a.Bytes())
-		copy(encrypted[256:], b)
+		copy(encrypted[256-len(a):256], a)
+		copy(encrypted[512-len(b):], b)
 	}
 	return
 }
@@ -190,6 +205,10 @@ func (elg ElgPublicKey) Len() int {
 	return len(elg)
 }
 
+func (elg ElgPublicKey) Bytes() []byte {
+	return elg[:]
+}
+
 func (elg ElgPublicKey) NewEncrypter() (enc Encrypter, err error) {
 	k := createElgamalPublicKey(elg[:])
 	enc, err = createElgamalEncryption(k, rand.Reader)
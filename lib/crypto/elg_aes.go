@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// size of an elgamal block with zero padding
+const elgBlockSize = 514
+
+// size of an elgamal encrypted tunnel build request record
+const TunnelBuildRecordSize = 528
+
+var ElgAESDecryptFail = errors.New("failed to decrypt elgamal/aes encrypted data")
+
+// encrypt a payload to an elgamal public key, i2p style
+// the elgamal block carries a fresh session key and pre-IV, the aes block carries the payload
+// see https://geti2p.net/en/docs/how/elgamal-aes
+func ElgAESEncrypt(pubKey ElgPublicKey, payload []byte) (encrypted []byte, err error) {
+	elgData := make([]byte, 222)
+	_, err = io.ReadFull(rand.Reader, elgData)
+	if err != nil {
+		return
+	}
+	sessionKey := elgData[:32]
+	preIV := elgData[32:64]
+	enc, err := createElgamalEncryption(createElgamalPublicKey(pubKey[:]), rand.Reader)
+	if err != nil {
+		return
+	}
+	elgBlock, err := enc.EncryptPadding(elgData, true)
+	if err != nil {
+		return
+	}
+
+	// tag count, payload size, payload hash, flag, payload then padding
+	blockLen := 2 + 4 + 32 + 1 + len(payload)
+	if rem := blockLen % aes.BlockSize; rem != 0 {
+		blockLen += aes.BlockSize - rem
+	}
+	block := make([]byte, blockLen)
+	binary.BigEndian.PutUint32(block[2:], uint32(len(payload)))
+	h := sha256.Sum256(payload)
+	copy(block[6:], h[:])
+	copy(block[39:], payload)
+	_, err = io.ReadFull(rand.Reader, block[39+len(payload):])
+	if err != nil {
+		return
+	}
+	c, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return
+	}
+	iv := sha256.Sum256(preIV)
+	cipher.NewCBCEncrypter(c, iv[:aes.BlockSize]).CryptBlocks(block, block)
+
+	encrypted = append(elgBlock, block...)
+	return
+}
+
+// decrypt data encrypted with ElgAESEncrypt, the ElGamal/AES+SessionTag format of garlic messages
+// returns the payload or nil and ElgAESDecryptFail if the data is malformed or does not verify
+func ElgAESDecrypt(privKey ElgPrivateKey, data []byte) (payload []byte, err error) {
+	if len(data) < elgBlockSize+aes.BlockSize || (len(data)-elgBlockSize)%aes.BlockSize != 0 {
+		err = ElgAESDecryptFail
+		return
+	}
+	elgData, err := elgamalDecrypt(createElgamalPrivateKey(privKey[:]), data[:elgBlockSize], true)
+	if err != nil {
+		err = ElgAESDecryptFail
+		return
+	}
+	c, err := aes.NewCipher(elgData[:32])
+	if err != nil {
+		err = ElgAESDecryptFail
+		return
+	}
+	iv := sha256.Sum256(elgData[32:64])
+	block := make([]byte, len(data)-elgBlockSize)
+	cipher.NewCBCDecrypter(c, iv[:aes.BlockSize]).CryptBlocks(block, data[elgBlockSize:])
+
+	// skip any session tags
+	idx := 2 + 32*int(binary.BigEndian.Uint16(block))
+	if idx+4+32+1 > len(block) {
+		err = ElgAESDecryptFail
+		return
+	}
+	size := binary.BigEndian.Uint32(block[idx:])
+	idx += 4
+	h := block[idx : idx+32]
+	idx += 32
+	if block[idx] == 0x01 {
+		// a new session key follows the flag
+		idx += 32
+	}
+	idx++
+	// compare as unsigned so a large size cannot wrap negative on 32 bit builds
+	if idx > len(block) || uint64(size) > uint64(len(block)-idx) {
+		err = ElgAESDecryptFail
+		return
+	}
+	payload = block[idx : idx+int(size)]
+	d := sha256.Sum256(payload)
+	if subtle.ConstantTimeCompare(d[:], h) != 1 {
+		payload = nil
+		err = ElgAESDecryptFail
+	}
+	return
+}
+
+// encrypt the 222 byte cleartext of a tunnel build request record to a hop
+// the record is the first 16 bytes of the hop's identity hash then an elgamal block without zero padding
+// see https://geti2p.net/spec/tunnel-creation
+func ElgEncryptBuildRecord(pubKey ElgPublicKey, identHash [32]byte, cleartext []byte) (record []byte, err error) {
+	enc, err := createElgamalEncryption(createElgamalPublicKey(pubKey[:]), rand.Reader)
+	if err != nil {
+		return
+	}
+	elgBlock, err := enc.EncryptPadding(cleartext, false)
+	if err != nil {
+		return
+	}
+	record = make([]byte, 0, TunnelBuildRecordSize)
+	record = append(record, identHash[:16]...)
+	record = append(record, elgBlock...)
+	return
+}
+
+// decrypt a tunnel build request record addressed to the hop with the given identity hash
+// returns the 222 byte cleartext or nil and ElgDecryptFail if the record is malformed,
+// addressed to another hop or does not verify
+func ElgDecryptBuildRecord(privKey ElgPrivateKey, identHash [32]byte, record []byte) (cleartext []byte, err error) {
+	if len(record) != TunnelBuildRecordSize || subtle.ConstantTimeCompare(record[:16], identHash[:16]) != 1 {
+		err = ElgDecryptFail
+		return
+	}
+	cleartext, err = elgamalDecrypt(createElgamalPrivateKey(privKey[:]), record[16:], false)
+	if err != nil {
+		cleartext = nil
+		err = ElgDecryptFail
+	}
+	return
+}
@@ -3,6 +3,7 @@ package crypto
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/openpgp/elgamal"
 	"io"
@@ -25,7 +26,7 @@ func BenchmarkElgDecrypt(b *testing.B) {
 	if err != nil {
 		panic(err.Error())
 	}
-	pub := createElgamalPublicKey(prv.Y.Bytes())
+	pub := createElgamalPublicKey(prv.Y.FillBytes(make([]byte, 256)))
 	enc, err := createElgamalEncryption(pub, rand.Reader)
 	if err != nil {
 		panic(err.Error())
@@ -55,7 +56,7 @@ func BenchmarkElgEncrypt(b *testing.B) {
 	if err != nil {
 		panic(err.Error())
 	}
-	pub := createElgamalPublicKey(prv.Y.Bytes())
+	pub := createElgamalPublicKey(prv.Y.FillBytes(make([]byte, 256)))
 	enc, err := createElgamalEncryption(pub, rand.Reader)
 	if err != nil {
 		panic(err.Error())
@@ -79,7 +80,7 @@ func TestElg(t *testing.T) {
 		msg := make([]byte, 222)
 		_, err := io.ReadFull(rand.Reader, msg)
 		if err == nil {
-			pub := createElgamalPublicKey(k.Y.Bytes())
+			pub := createElgamalPublicKey(k.Y.FillBytes(make([]byte, 256)))
 			enc, err := createElgamalEncryption(pub, rand.Reader)
 			if err == nil {
 				emsg, err := enc.Encrypt(msg)
@@ -113,3 +114,116 @@ func TestElg(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestElgAES(t *testing.T) {
+	k := new(elgamal.PrivateKey)
+	err := ElgamalGenerate(k, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pub ElgPublicKey
+	var priv ElgPrivateKey
+	yb := k.Y.Bytes()
+	copy(pub[len(pub)-len(yb):], yb)
+	xb := k.X.Bytes()
+	copy(priv[len(priv)-len(xb):], xb)
+
+	msg := make([]byte, 528)
+	_, err = io.ReadFull(rand.Reader, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := ElgAESEncrypt(pub, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := ElgAESDecrypt(priv, enc)
+	if err != nil {
+		t.Fatalf("failed to decrypt elgamal/aes: %s", err)
+	}
+	if !bytes.Equal(dec, msg) {
+		t.Log("elgamal/aes decrypted payload does not match")
+		t.Fail()
+	}
+
+	enc[len(enc)-1] ^= 0xff
+	if _, err = ElgAESDecrypt(priv, enc); err == nil {
+		t.Log("elgamal/aes accepted tampered data")
+		t.Fail()
+	}
+	if _, err = ElgAESDecrypt(priv, enc[:100]); err != ElgAESDecryptFail {
+		t.Log("elgamal/aes accepted truncated data")
+		t.Fail()
+	}
+}
+
+func TestElgAESDecryptRejectsMalformedData(t *testing.T) {
+	k := new(elgamal.PrivateKey)
+	err := ElgamalGenerate(k, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var priv ElgPrivateKey
+	k.X.FillBytes(priv[:])
+
+	for _, data := range [][]byte{make([]byte, 530), make([]byte, 1042)} {
+		if _, err = ElgAESDecrypt(priv, data); err != ElgAESDecryptFail {
+			t.Fatalf("expected ElgAESDecryptFail for %d zero bytes, got %v", len(data), err)
+		}
+	}
+	for n := 0; n < 64; n++ {
+		data := make([]byte, elgBlockSize+16*(n%4+1))
+		_, err = io.ReadFull(rand.Reader, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ElgAESDecrypt(priv, data); err != ElgAESDecryptFail {
+			t.Fatalf("expected ElgAESDecryptFail for random data, got %v", err)
+		}
+	}
+}
+
+func TestElgBuildRecord(t *testing.T) {
+	k := new(elgamal.PrivateKey)
+	err := ElgamalGenerate(k, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pub ElgPublicKey
+	var priv ElgPrivateKey
+	k.Y.FillBytes(pub[:])
+	k.X.FillBytes(priv[:])
+	identHash := sha256.Sum256([]byte("hop"))
+
+	cleartext := make([]byte, 222)
+	_, err = io.ReadFull(rand.Reader, cleartext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := ElgEncryptBuildRecord(pub, identHash, cleartext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record) != TunnelBuildRecordSize {
+		t.Fatalf("build record is %d bytes, expected %d", len(record), TunnelBuildRecordSize)
+	}
+	dec, err := ElgDecryptBuildRecord(priv, identHash, record)
+	if err != nil {
+		t.Fatalf("failed to decrypt build record: %s", err)
+	}
+	if !bytes.Equal(dec, cleartext) {
+		t.Fatal("decrypted build record does not match")
+	}
+
+	otherHash := sha256.Sum256([]byte("other hop"))
+	if _, err = ElgDecryptBuildRecord(priv, otherHash, record); err != ElgDecryptFail {
+		t.Fatalf("expected ElgDecryptFail for a record to another hop, got %v", err)
+	}
+	if _, err = ElgDecryptBuildRecord(priv, identHash, record[:TunnelBuildRecordSize-1]); err != ElgDecryptFail {
+		t.Fatalf("expected ElgDecryptFail for a truncated record, got %v", err)
+	}
+	record[TunnelBuildRecordSize-1] ^= 0xff
+	if _, err = ElgDecryptBuildRecord(priv, identHash, record); err != ElgDecryptFail {
+		t.Fatalf("expected ElgDecryptFail for a tampered record, got %v", err)
+	}
+}
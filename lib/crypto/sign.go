@@ -24,10 +24,14 @@ type SigningPublicKey interface {
 	NewVerifier() (Verifier, error)
 	// get the size of this public key
 	Len() int
+	// get the raw bytes of this public key
+	Bytes() []byte
 }
 
 type PublicKey interface {
 	Len() int
+	// get the raw bytes of this public key
+	Bytes() []byte
 	NewEncrypter() (Encrypter, error)
 }
 
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+var (
+	_ Signer   = &DSASigner{}
+	_ Signer   = &Ed25519Signer{}
+	_ Verifier = &DSAVerifier{}
+	_ Verifier = &Ed25519Verifier{}
+	_ Verifier = &ECDSAVerifier{}
+)
+
+// sign and verify an empty message with a signer and verifier pair
+func signVerifyEmpty(t *testing.T, signer Signer, verifier Verifier) {
+	sig, err := signer.Sign([]byte{})
+	if err != nil {
+		t.Fatalf("failed to sign empty message: %s", err)
+	}
+	if err = verifier.Verify([]byte{}, sig); err != nil {
+		t.Fatalf("failed to verify empty message: %s", err)
+	}
+	if err = verifier.Verify([]byte{0x00}, sig); err == nil {
+		t.Fatal("signature over empty message verified a different message")
+	}
+}
+
+func TestDSASignEmptyMessage(t *testing.T) {
+	var sk DSAPrivateKey
+	sk, err := sk.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := sk.NewSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := pk.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signVerifyEmpty(t, signer, verifier)
+}
+
+func TestEd25519SignEmptyMessage(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk := Ed25519PrivateKey(priv)
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := sk.NewSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := pk.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signVerifyEmpty(t, signer, verifier)
+}
@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"golang.org/x/crypto/curve25519"
+	"io"
+)
+
+// x25519 keys are only used for key agreement, there is no block encryption with them
+var ErrX25519NoEncrypter = errors.New("x25519 keys do not support direct encryption")
+
+type X25519PublicKey [32]byte
+
+type X25519PrivateKey [32]byte
+
+func (k X25519PublicKey) Len() int {
+	return len(k)
+}
+
+func (k X25519PublicKey) Bytes() []byte {
+	return k[:]
+}
+
+// x25519 keys are used through key agreement by the protocols that carry them
+func (k X25519PublicKey) NewEncrypter() (enc Encrypter, err error) {
+	err = ErrX25519NoEncrypter
+	return
+}
+
+func (k X25519PrivateKey) Len() int {
+	return len(k)
+}
+
+// generate a new random x25519 private key
+func (k X25519PrivateKey) Generate() (priv X25519PrivateKey, err error) {
+	_, err = io.ReadFull(rand.Reader, priv[:])
+	if err == nil {
+		// clamp as described in rfc 7748
+		priv[0] &= 248
+		priv[31] &= 127
+		priv[31] |= 64
+	}
+	return
+}
+
+// get the public key for this private key
+func (k X25519PrivateKey) Public() (pub X25519PublicKey, err error) {
+	b, err := curve25519.X25519(k[:], curve25519.Basepoint)
+	if err == nil {
+		copy(pub[:], b)
+	}
+	return
+}
+
+// compute the shared secret between this private key and a peer's public key
+func (k X25519PrivateKey) SharedKey(pub X25519PublicKey) (shared []byte, err error) {
+	shared, err = curve25519.X25519(k[:], pub[:])
+	return
+}
@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestX25519SharedKeyAgrees(t *testing.T) {
+	var alice, bob X25519PrivateKey
+	alice, err := alice.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err = bob.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alicePub, err := alice.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobPub, err := bob.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, err := alice.SharedKey(bobPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := bob.SharedKey(alicePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s1, s2) {
+		t.Log("x25519 shared keys differ")
+		t.Fail()
+	}
+}
@@ -0,0 +1,58 @@
+package netdb
+
+import (
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/common"
+	"sync"
+)
+
+// returned by RouterInfoStore.Put when a newer RouterInfo for the same router is stored
+var ErrOlderRouterInfo = errors.New("router info is older than the one already stored")
+
+// in memory RouterInfos keyed by identity hash, keeping only the newest published
+// RouterInfo of each router
+// safe for concurrent use
+type RouterInfoStore struct {
+	mtx     sync.RWMutex
+	entries map[common.Hash]common.RouterInfo
+}
+
+// create an empty RouterInfoStore
+func NewRouterInfoStore() *RouterInfoStore {
+	return &RouterInfoStore{
+		entries: make(map[common.Hash]common.RouterInfo),
+	}
+}
+
+// store a copy of a RouterInfo, replacing any stored RouterInfo for the same router
+// unless the stored one was published later
+// the caller may reuse ri afterwards without changing what is stored
+func (s *RouterInfoStore) Put(ri common.RouterInfo) (err error) {
+	ident, err := ri.IdentHash()
+	if err != nil {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if stored, ok := s.entries[ident]; ok && stored.IsNewerThan(ri) {
+		err = ErrOlderRouterInfo
+		return
+	}
+	s.entries[ident] = append(common.RouterInfo(nil), ri...)
+	return
+}
+
+// get the stored RouterInfo for the router with this identity hash
+func (s *RouterInfoStore) Get(hash common.Hash) (ri common.RouterInfo, ok bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	ri, ok = s.entries[hash]
+	return
+}
+
+// number of routers stored
+func (s *RouterInfoStore) Count() int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return len(s.entries)
+}
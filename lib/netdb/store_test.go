@@ -0,0 +1,93 @@
+package netdb
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common"
+	"sync"
+	"testing"
+)
+
+// build a RouterInfo for the router identified by seed, published at ms milliseconds
+func buildPublishedRouterInfo(seed byte, ms uint64) common.RouterInfo {
+	data := make([]byte, common.KEYS_AND_CERT_MIN_SIZE+8)
+	data[0] = seed
+	for i := 0; i < 8; i++ {
+		data[common.KEYS_AND_CERT_MIN_SIZE+i] = byte(ms >> (56 - 8*uint(i)))
+	}
+	return common.RouterInfo(data)
+}
+
+func TestRouterInfoStoreRejectsOlder(t *testing.T) {
+	s := NewRouterInfoStore()
+	newer := buildPublishedRouterInfo(1, 2000)
+	older := buildPublishedRouterInfo(1, 1000)
+	if err := s.Put(newer); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.Put(older); err != ErrOlderRouterInfo {
+		t.Fatalf("expected ErrOlderRouterInfo, got %v", err)
+	}
+	ident, _ := newer.IdentHash()
+	ri, ok := s.Get(ident)
+	if !ok {
+		t.Fatal("stored router info not found")
+	}
+	if !ri.IsNewerThan(older) {
+		t.Fatal("older router info replaced the newer one")
+	}
+	if s.Count() != 1 {
+		t.Fatalf("expected 1 router, got %d", s.Count())
+	}
+}
+
+func TestRouterInfoStoreReplacesWithNewer(t *testing.T) {
+	s := NewRouterInfoStore()
+	s.Put(buildPublishedRouterInfo(1, 1000))
+	newer := buildPublishedRouterInfo(1, 2000)
+	if err := s.Put(newer); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ident, _ := newer.IdentHash()
+	ri, _ := s.Get(ident)
+	if published, _ := ri.Published(); published.Time().UnixNano()/1000000 != 2000 {
+		t.Fatalf("newer router info was not stored")
+	}
+}
+
+func TestRouterInfoStorePutCopiesRouterInfo(t *testing.T) {
+	s := NewRouterInfoStore()
+	ri := buildPublishedRouterInfo(1, 1000)
+	ident, _ := ri.IdentHash()
+	if err := s.Put(ri); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// reuse the caller's buffer for another publication date
+	copy(ri, buildPublishedRouterInfo(1, 3000))
+	stored, _ := s.Get(ident)
+	if published, _ := stored.Published(); published.Time().UnixNano()/1000000 != 1000 {
+		t.Fatal("changing the RouterInfo passed to Put changed the stored one")
+	}
+}
+
+func TestRouterInfoStoreConcurrentAccess(t *testing.T) {
+	s := NewRouterInfoStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(seed byte) {
+			defer wg.Done()
+			for ms := uint64(1); ms <= 32; ms++ {
+				ri := buildPublishedRouterInfo(seed, ms)
+				s.Put(ri)
+				ident, _ := ri.IdentHash()
+				if _, ok := s.Get(ident); !ok {
+					t.Errorf("router info for seed %d not found", seed)
+				}
+				s.Count()
+			}
+		}(byte(i))
+	}
+	wg.Wait()
+	if s.Count() != 16 {
+		t.Fatalf("expected 16 routers, got %d", s.Count())
+	}
+}
@@ -0,0 +1,89 @@
+package netdb
+
+import (
+	"container/list"
+	"github.com/go-i2p/go-i2p/lib/common"
+	"sync"
+)
+
+// remembers the outcome of verifying RouterInfo signatures so the same RouterInfo
+// is not verified again each time it is seen
+// entries are keyed by the identity hash and the hash of the whole RouterInfo, so any
+// change to the signed data or the signature is verified afresh
+// safe for concurrent use
+type VerificationCache struct {
+	// verifies a RouterInfo on a cache miss
+	verify func(common.RouterInfo) error
+	// most entries to keep before evicting the least recently used
+	size    int
+	mtx     sync.Mutex
+	order   *list.List
+	entries map[verificationKey]*list.Element
+}
+
+// key of a cached verification
+type verificationKey struct {
+	ident   common.Hash
+	content common.Hash
+}
+
+// a cached verification and its place in the lru order
+type verificationEntry struct {
+	key verificationKey
+	err error
+}
+
+// create a cache holding the outcome of at most size verifications
+func NewVerificationCache(size int) *VerificationCache {
+	return &VerificationCache{
+		verify:  common.RouterInfo.Verify,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[verificationKey]*list.Element),
+	}
+}
+
+// verify the signature of a RouterInfo, reusing an earlier result for the same RouterInfo
+// returns nil if the signature is valid
+func (c *VerificationCache) Verify(ri common.RouterInfo) (err error) {
+	ident, err := ri.IdentHash()
+	if err != nil {
+		return
+	}
+	key := verificationKey{
+		ident:   ident,
+		content: common.HashData(ri),
+	}
+	c.mtx.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		err = elem.Value.(*verificationEntry).err
+		c.mtx.Unlock()
+		return
+	}
+	c.mtx.Unlock()
+
+	// verify without holding the lock, a concurrent miss on the same key only costs a
+	// duplicate verification
+	err = c.verify(ri)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, ok := c.entries[key]; ok || c.size <= 0 {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&verificationEntry{key: key, err: err})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*verificationEntry).key)
+	}
+	return
+}
+
+// number of verifications currently cached
+func (c *VerificationCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,86 @@
+package netdb
+
+import (
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/common"
+	"github.com/go-i2p/go-i2p/lib/common/testutil"
+	"testing"
+)
+
+// build a RouterInfo that differs from others built with a different seed
+func buildRouterInfo(seed byte) common.RouterInfo {
+	data := make([]byte, common.KEYS_AND_CERT_MIN_SIZE)
+	data[0] = seed
+	return common.RouterInfo(data)
+}
+
+// count verifications reaching the underlying verifier
+func countingCache(size int, result error) (*VerificationCache, *int) {
+	calls := new(int)
+	c := NewVerificationCache(size)
+	c.verify = func(ri common.RouterInfo) error {
+		*calls++
+		return result
+	}
+	return c, calls
+}
+
+func TestVerificationCacheHitsOnSecondVerify(t *testing.T) {
+	c, calls := countingCache(4, nil)
+	ri := buildRouterInfo(1)
+	for i := 0; i < 2; i++ {
+		if err := c.Verify(ri); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("expected 1 verification, got %d", *calls)
+	}
+}
+
+func TestVerificationCacheRemembersFailures(t *testing.T) {
+	invalid := errors.New("invalid signature")
+	c, calls := countingCache(4, invalid)
+	ri := buildRouterInfo(1)
+	for i := 0; i < 2; i++ {
+		if err := c.Verify(ri); err != invalid {
+			t.Fatalf("expected cached failure, got %v", err)
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("expected 1 verification, got %d", *calls)
+	}
+}
+
+func TestVerificationCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, calls := countingCache(2, nil)
+	c.Verify(buildRouterInfo(1))
+	c.Verify(buildRouterInfo(2))
+	c.Verify(buildRouterInfo(1))
+	c.Verify(buildRouterInfo(3))
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 cached verifications, got %d", c.Len())
+	}
+	// 1 was used more recently than 2 so it is still cached
+	c.Verify(buildRouterInfo(1))
+	if *calls != 3 {
+		t.Fatalf("expected 3 verifications, got %d", *calls)
+	}
+	c.Verify(buildRouterInfo(2))
+	if *calls != 4 {
+		t.Fatalf("expected evicted RouterInfo to be verified again, got %d verifications", *calls)
+	}
+}
+
+func TestVerificationCacheReportsTruncatedRouterInfo(t *testing.T) {
+	c := NewVerificationCache(8)
+	ri := testutil.MinimalRouterInfo(t)
+	if err := c.Verify(ri); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, n := range []int{common.KEYS_AND_CERT_MIN_SIZE + 8, 415, len(ri) - 1} {
+		if err := c.Verify(ri[:n]); err == nil {
+			t.Fatalf("expected an error verifying %d of %d bytes", n, len(ri))
+		}
+	}
+}
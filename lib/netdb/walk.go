@@ -0,0 +1,81 @@
+package netdb
+
+import (
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/common"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// returned when a RouterInfo file is larger than any RouterInfo we will parse
+var ErrRouterInfoFileTooLarge = errors.New("router info file is too large")
+
+// read and parse a RouterInfo stored in a file, which may be gzip compressed
+// files larger than the default RouterInfo size limit are rejected without being read
+func LoadRouterInfoFile(fpath string) (ri common.RouterInfo, err error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	if info.Size() > common.PARSE_LIMIT_MAX_ROUTER_INFO_SIZE {
+		err = ErrRouterInfoFileTooLarge
+		return
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(f, common.PARSE_LIMIT_MAX_ROUTER_INFO_SIZE+1))
+	if err != nil {
+		return
+	}
+	if len(data) > common.PARSE_LIMIT_MAX_ROUTER_INFO_SIZE {
+		// the file grew after we checked its size
+		err = ErrRouterInfoFileTooLarge
+		return
+	}
+	ri, err = common.ReadRouterInfoMaybeCompressed(data, common.DefaultParseLimits())
+	return
+}
+
+// call fn with each RouterInfo stored in a routerInfo-*.dat file under dir, such as the
+// netDb directory of a local I2P installation
+// files and subdirectories that cannot be read or parsed are logged and skipped
+// stops and returns the first error returned by fn, or the error reading dir itself
+func WalkNetDB(dir string, fn func(common.RouterInfo) error) error {
+	return filepath.Walk(dir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if fpath == dir {
+				return err
+			}
+			log.WithFields(log.Fields{
+				"at":    "WalkNetDB",
+				"file":  fpath,
+				"error": err,
+			}).Warn("skipping unreadable netdb entry")
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if !strings.HasPrefix(name, "routerInfo-") || !strings.HasSuffix(name, ".dat") {
+			return nil
+		}
+		ri, err := LoadRouterInfoFile(fpath)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"at":    "WalkNetDB",
+				"file":  fpath,
+				"error": err,
+			}).Warn("skipping unparseable router info")
+			return nil
+		}
+		return fn(ri)
+	})
+}
@@ -0,0 +1,127 @@
+package netdb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-i2p/go-i2p/lib/common"
+	"github.com/go-i2p/go-i2p/lib/common/testutil"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// write a RouterInfo for the router identified by seed into dir
+func writeRouterInfoFile(t *testing.T, dir string, seed byte) common.RouterInfo {
+	ri := testutil.BuildRouterInfo(t, testutil.RouterInfoConfig{Seed: seed})
+	ident, _ := ri.IdentHash()
+	db := StdNetDB(dir)
+	fpath := db.SkiplistFile(ident)
+	if err := ioutil.WriteFile(fpath, ri, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return ri
+}
+
+// create a skiplist netdb in a temporary directory
+func createTestNetDB(t *testing.T) StdNetDB {
+	db := StdNetDB(filepath.Join(t.TempDir(), "netDb"))
+	if err := db.Create(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestWalkNetDBSkipsCorruptFiles(t *testing.T) {
+	db := createTestNetDB(t)
+	writeRouterInfoFile(t, db.Path(), 1)
+	writeRouterInfoFile(t, db.Path(), 2)
+	corrupt := filepath.Join(db.Path(), "rA", "routerInfo-corrupt.dat")
+	if err := ioutil.WriteFile(corrupt, []byte{0x00, 0x01, 0x02}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(db.Path(), "rA", "notes.txt"), []byte("hi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[common.Hash]bool)
+	err := WalkNetDB(db.Path(), func(ri common.RouterInfo) error {
+		ident, err := ri.IdentHash()
+		seen[ident] = true
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("expected 2 router infos, got %d", len(seen))
+	}
+}
+
+func TestWalkNetDBStopsOnCallbackError(t *testing.T) {
+	db := createTestNetDB(t)
+	writeRouterInfoFile(t, db.Path(), 1)
+	writeRouterInfoFile(t, db.Path(), 2)
+
+	stop := errors.New("stop")
+	calls := 0
+	err := WalkNetDB(db.Path(), func(ri common.RouterInfo) error {
+		calls++
+		return stop
+	})
+	if err != stop {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 callback, got %d", calls)
+	}
+}
+
+func TestLoadRouterInfoFileRejectsLargeFile(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "routerInfo-large.dat")
+	if err := ioutil.WriteFile(fpath, make([]byte, common.PARSE_LIMIT_MAX_ROUTER_INFO_SIZE+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRouterInfoFile(fpath); err != ErrRouterInfoFileTooLarge {
+		t.Fatalf("expected ErrRouterInfoFileTooLarge, got %v", err)
+	}
+}
+
+func TestWalkNetDBSkipsLargeAndTruncatedFiles(t *testing.T) {
+	db := createTestNetDB(t)
+	ri := writeRouterInfoFile(t, db.Path(), 1)
+	large := filepath.Join(db.Path(), "rA", "routerInfo-large.dat")
+	if err := ioutil.WriteFile(large, make([]byte, common.PARSE_LIMIT_MAX_ROUTER_INFO_SIZE+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for length := 1; length < len(ri); length += 37 {
+		truncated := filepath.Join(db.Path(), "rB", fmt.Sprintf("routerInfo-truncated%d.dat", length))
+		if err := ioutil.WriteFile(truncated, ri[:length], 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	calls := 0
+	err := WalkNetDB(db.Path(), func(ri common.RouterInfo) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 router info, got %d", calls)
+	}
+}
+
+func TestLoadRouterInfoFileReportsEveryTruncation(t *testing.T) {
+	ri := testutil.MinimalRouterInfo(t)
+	fpath := filepath.Join(t.TempDir(), "routerInfo-truncated.dat")
+	for length := 0; length < len(ri); length++ {
+		if err := ioutil.WriteFile(fpath, ri[:length], 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRouterInfoFile(fpath); err == nil {
+			t.Fatalf("expected an error for a RouterInfo truncated to %d bytes", length)
+		}
+	}
+}
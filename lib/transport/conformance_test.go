@@ -0,0 +1,11 @@
+package transport_test
+
+import (
+	"github.com/go-i2p/go-i2p/lib/transport"
+	"github.com/go-i2p/go-i2p/lib/transport/transporttest"
+	"testing"
+)
+
+func TestLoopbackSessionConformance(t *testing.T) {
+	transporttest.TestTransportSessionConformance(t, transport.NewLoopbackSession)
+}
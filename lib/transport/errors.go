@@ -6,3 +6,14 @@ import (
 
 // error for when we have no transports available to use
 var ErrNoTransportAvailable = errors.New("no transports available")
+
+// error for when none of our transports can talk to any of a peer's addresses
+// the peer's RouterInfo may be stale so callers may want to look up a fresh one
+var ErrNoCompatibleTransport = errors.New("no compatible transport")
+
+// error for when a peer is on a different i2p network than us
+// this is not recoverable by retrying so callers should give up on the peer
+var ErrNetworkMismatch = errors.New("peer is on a different network")
+
+// error for when a transport is registered under a name that is already taken
+var ErrTransportRegistered = errors.New("a transport is already registered under that name")
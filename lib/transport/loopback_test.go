@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/i2np"
+)
+
+// in memory session that loops queued messages back to the reader
+type loopbackSession struct {
+	msgs   chan i2np.I2NPMessage
+	closed chan struct{}
+}
+
+func newLoopbackSession() (TransportSession, error) {
+	return &loopbackSession{
+		msgs:   make(chan i2np.I2NPMessage, 1),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+func (s *loopbackSession) QueueSendI2NP(msg i2np.I2NPMessage) {
+	s.msgs <- msg
+}
+
+func (s *loopbackSession) SendQueueSize() int {
+	return len(s.msgs)
+}
+
+func (s *loopbackSession) ReadNextI2NP() (msg i2np.I2NPMessage, err error) {
+	select {
+	case <-s.closed:
+		err = errors.New("session closed")
+		return
+	default:
+	}
+	select {
+	case <-s.closed:
+		err = errors.New("session closed")
+	case msg = <-s.msgs:
+	}
+	return
+}
+
+func (s *loopbackSession) Close() error {
+	close(s.closed)
+	return nil
+}
+
+// lets the external conformance test use the loopback session
+var NewLoopbackSession = newLoopbackSession
@@ -0,0 +1,31 @@
+package transport
+
+// callbacks a transport invokes so operators can count handshakes and traffic
+// implementations must be safe to call from multiple sessions at once
+type Metrics interface {
+	// a handshake with a peer completed
+	HandshakeSucceeded()
+	// a handshake with a peer failed with err
+	HandshakeFailed(err error)
+	// n bytes were written to a peer
+	BytesSent(n int)
+	// n bytes were read from a peer
+	BytesReceived(n int)
+}
+
+// optionally implemented by a Transport that reports to a Metrics
+type MetricsTransport interface {
+	Transport
+	// report this transport's handshakes and traffic to m
+	// a nil m stops reporting
+	SetMetrics(m Metrics)
+}
+
+// Metrics that discards everything
+// used by transports that have not been given a Metrics
+type NopMetrics struct{}
+
+func (NopMetrics) HandshakeSucceeded()       {}
+func (NopMetrics) HandshakeFailed(err error) {}
+func (NopMetrics) BytesSent(n int)           {}
+func (NopMetrics) BytesReceived(n int)       {}
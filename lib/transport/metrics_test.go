@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common"
+	"testing"
+)
+
+// Metrics that discards everything but can be told apart from NopMetrics
+type countingMetrics struct {
+	NopMetrics
+	id int
+}
+
+// transport that remembers the metrics it was given
+type metricsTransport struct {
+	metrics Metrics
+}
+
+func (t *metricsTransport) SetMetrics(m Metrics)                          { t.metrics = m }
+func (t *metricsTransport) SetIdentity(ident common.RouterIdentity) error { return nil }
+func (t *metricsTransport) Compatable(routerInfo common.RouterInfo) bool  { return true }
+func (t *metricsTransport) Close() error                                  { return nil }
+func (t *metricsTransport) Name() string                                  { return "metrics" }
+
+func (t *metricsTransport) GetSession(routerInfo common.RouterInfo) (TransportSession, error) {
+	return newLoopbackSession()
+}
+
+func TestMuxerSetMetricsReachesTransports(t *testing.T) {
+	metrics := &countingMetrics{id: 1}
+	first := new(metricsTransport)
+	second := new(metricsTransport)
+	// a transport without metrics support is skipped
+	plain := &styleTransport{style: "NTCP2"}
+	tmux := Mux(first, plain, second)
+	tmux.SetMetrics(metrics)
+
+	if first.metrics != Metrics(metrics) || second.metrics != Metrics(metrics) {
+		t.Fatal("SetMetrics did not reach every transport that reports metrics")
+	}
+}
+
+func TestMuxerSetMetricsReachesRegisteredTransports(t *testing.T) {
+	metrics := &countingMetrics{id: 2}
+	registry := NewRegistry()
+	tmux := MuxRegistry(registry)
+	registered := new(metricsTransport)
+	if err := registry.Register("metrics", registered); err != nil {
+		t.Fatal(err)
+	}
+	tmux.SetMetrics(metrics)
+
+	if registered.metrics != Metrics(metrics) {
+		t.Fatal("SetMetrics did not reach a registered transport")
+	}
+}
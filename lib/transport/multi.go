@@ -9,6 +9,10 @@ import (
 type TransportMuxer struct {
 	// the underlying transports we are using in order of most prominant to least
 	trans []Transport
+	// where the underlying transports are looked up instead of trans if set
+	registry *Registry
+	// the network we admit peers from, MainNetworkID if zero
+	networkID int
 }
 
 // mux a bunch of transports together
@@ -18,9 +22,25 @@ func Mux(t ...Transport) (tmux *TransportMuxer) {
 	return
 }
 
+// mux the transports in a registry together in the order they were registered
+// transports registered later are picked up by the muxer too
+func MuxRegistry(r *Registry) (tmux *TransportMuxer) {
+	tmux = new(TransportMuxer)
+	tmux.registry = r
+	return
+}
+
+// the underlying transports in order of most prominant to least
+func (tmux *TransportMuxer) transports() []Transport {
+	if tmux.registry != nil {
+		return tmux.registry.Transports()
+	}
+	return tmux.trans
+}
+
 // set the identity for every transport
 func (tmux *TransportMuxer) SetIdentity(ident common.RouterIdentity) (err error) {
-	for _, t := range tmux.trans {
+	for _, t := range tmux.transports() {
 		err = t.SetIdentity(ident)
 		if err != nil {
 			// an error happened let's return and complain
@@ -30,9 +50,31 @@ func (tmux *TransportMuxer) SetIdentity(ident common.RouterIdentity) (err error)
 	return
 }
 
+// only admit peers on the network with this id
+func (tmux *TransportMuxer) SetNetworkID(networkID int) {
+	tmux.networkID = networkID
+}
+
+// the network we admit peers from
+func (tmux *TransportMuxer) getNetworkID() int {
+	if tmux.networkID == 0 {
+		return MainNetworkID
+	}
+	return tmux.networkID
+}
+
+// set the metrics for every transport that reports metrics
+func (tmux *TransportMuxer) SetMetrics(m Metrics) {
+	for _, t := range tmux.transports() {
+		if mt, ok := t.(MetricsTransport); ok {
+			mt.SetMetrics(m)
+		}
+	}
+}
+
 // close every transport that this transport muxer has
 func (tmux *TransportMuxer) Close() (err error) {
-	for _, t := range tmux.trans {
+	for _, t := range tmux.transports() {
 		err = t.Close()
 		if t != nil {
 			// TODO: handle error (?)
@@ -44,7 +86,7 @@ func (tmux *TransportMuxer) Close() (err error) {
 // the name of this transport with the names of all the ones that we mux
 func (tmux *TransportMuxer) Name() string {
 	name := "Muxed Transport: "
-	for _, t := range tmux.trans {
+	for _, t := range tmux.transports() {
 		name += t.Name() + ", "
 	}
 	return name[len(name)-3:]
@@ -52,11 +94,20 @@ func (tmux *TransportMuxer) Name() string {
 
 // get a transport session given a router info
 // return session and nil if successful
+// return nil and ErrNetworkMismatch if the router is not on our network
+// return nil and ErrNoCompatibleTransport if none of our transports are compatable with the router info
 // return nil and ErrNoTransportAvailable if we failed to get a session
 func (tmux *TransportMuxer) GetSession(routerInfo common.RouterInfo) (s TransportSession, err error) {
-	for _, t := range tmux.trans {
+	// don't try to handshake with routers from other networks
+	err = CheckNetworkID(routerInfo, tmux.getNetworkID())
+	if err != nil {
+		return
+	}
+	compat := false
+	for _, t := range tmux.transports() {
 		// pick the first one that is compatable
 		if t.Compatable(routerInfo) {
+			compat = true
 			// try to get a session
 			s, err = t.GetSession(routerInfo)
 			if err != nil {
@@ -68,6 +119,11 @@ func (tmux *TransportMuxer) GetSession(routerInfo common.RouterInfo) (s Transpor
 			return
 		}
 	}
+	if !compat {
+		// nothing we have can talk to this routerInfo
+		err = ErrNoCompatibleTransport
+		return
+	}
 	// we failed to get a session for this routerInfo
 	err = ErrNoTransportAvailable
 	return
@@ -75,7 +131,7 @@ func (tmux *TransportMuxer) GetSession(routerInfo common.RouterInfo) (s Transpor
 
 // is there a transport that we mux that is compatable with this router info?
 func (tmux *TransportMuxer) Compatable(routerInfo common.RouterInfo) (compat bool) {
-	for _, t := range tmux.trans {
+	for _, t := range tmux.transports() {
 		if t.Compatable(routerInfo) {
 			compat = true
 			return
@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/common"
+	"github.com/go-i2p/go-i2p/lib/common/testutil"
+	"testing"
+)
+
+// transport that is compatable with router infos publishing an address of its style
+type styleTransport struct {
+	style string
+	fail  bool
+}
+
+func (t *styleTransport) SetIdentity(ident common.RouterIdentity) error { return nil }
+func (t *styleTransport) Close() error                                  { return nil }
+func (t *styleTransport) Name() string                                  { return t.style }
+
+func (t *styleTransport) Compatable(routerInfo common.RouterInfo) bool {
+	for _, style := range routerInfo.TransportStyles() {
+		if style == t.style {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *styleTransport) GetSession(routerInfo common.RouterInfo) (TransportSession, error) {
+	if t.fail {
+		return nil, errors.New("handshake failed")
+	}
+	return newLoopbackSession()
+}
+
+// build a router info with a single address of the given transport style
+func buildRouterInfoWithStyle(t *testing.T, style string) common.RouterInfo {
+	address, err := common.NewRouterAddressFromComponents(0, common.Date{}, style, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testutil.BuildRouterInfo(t, testutil.RouterInfoConfig{
+		Addresses: []common.RouterAddress{address},
+	})
+}
+
+func TestMuxerGetSessionWithNoCompatibleTransport(t *testing.T) {
+	tmux := Mux(&styleTransport{style: "NTCP2"}, &styleTransport{style: "SSU2"})
+	_, err := tmux.GetSession(buildRouterInfoWithStyle(t, "UNKNOWN"))
+	if err != ErrNoCompatibleTransport {
+		t.Fatalf("expected ErrNoCompatibleTransport, got %v", err)
+	}
+}
+
+func TestMuxerGetSessionWhenCompatibleTransportFails(t *testing.T) {
+	tmux := Mux(&styleTransport{style: "NTCP2", fail: true})
+	_, err := tmux.GetSession(buildRouterInfoWithStyle(t, "NTCP2"))
+	if err != ErrNoTransportAvailable {
+		t.Fatalf("expected ErrNoTransportAvailable, got %v", err)
+	}
+}
+
+func TestMuxerGetSessionWithCompatibleTransport(t *testing.T) {
+	tmux := Mux(&styleTransport{style: "SSU2"}, &styleTransport{style: "NTCP2"})
+	s, err := tmux.GetSession(buildRouterInfoWithStyle(t, "NTCP2"))
+	if err != nil {
+		t.Fatalf("GetSession failed: %s", err)
+	}
+	s.Close()
+}
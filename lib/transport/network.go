@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common"
+	"strconv"
+)
+
+// the network id of the main i2p network
+const MainNetworkID = 2
+
+// check that a peer's RouterInfo advertises the network id we are on
+// returns ErrNetworkMismatch if the peer is on another network
+// returns ErrNetworkMismatch if the peer does not publish a network id, netId is
+// required so we cannot tell which network such a peer is on
+// returns nil if the peer is on our network
+// returns the parse error if the RouterInfo is too short to hold its options
+func CheckNetworkID(routerInfo common.RouterInfo, networkID int) error {
+	options, err := routerInfo.CheckedOptions()
+	if err != nil {
+		return err
+	}
+	value, present := options.Get(common.ROUTER_INFO_OPTION_NET_ID)
+	if !present {
+		return ErrNetworkMismatch
+	}
+	peerID, err := strconv.Atoi(value)
+	if err != nil || peerID != networkID {
+		return ErrNetworkMismatch
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common"
+	"github.com/go-i2p/go-i2p/lib/common/testutil"
+	"testing"
+)
+
+func buildRouterInfoOnNetwork(t *testing.T, netID string) common.RouterInfo {
+	return testutil.BuildRouterInfo(t, testutil.RouterInfoConfig{
+		Options: map[string]string{common.ROUTER_INFO_OPTION_NET_ID: netID},
+	})
+}
+
+func TestCheckNetworkIDRejectsTestnetPeer(t *testing.T) {
+	err := CheckNetworkID(buildRouterInfoOnNetwork(t, "3"), MainNetworkID)
+	if err != ErrNetworkMismatch {
+		t.Fatalf("expected ErrNetworkMismatch for testnet peer, got %v", err)
+	}
+}
+
+func TestCheckNetworkIDRejectsMissingNetworkID(t *testing.T) {
+	err := CheckNetworkID(buildRouterInfoOnNetwork(t, ""), MainNetworkID)
+	if err != ErrNetworkMismatch {
+		t.Fatalf("expected ErrNetworkMismatch for peer without a netId, got %v", err)
+	}
+}
+
+func TestCheckNetworkIDAcceptsSameNetwork(t *testing.T) {
+	if err := CheckNetworkID(testutil.MinimalRouterInfo(t), MainNetworkID); err != nil {
+		t.Fatalf("unexpected error for peer on our network: %s", err)
+	}
+}
+
+func TestCheckNetworkIDReportsTruncatedRouterInfo(t *testing.T) {
+	routerInfo := testutil.MinimalRouterInfo(t)
+	for length := 0; length < len(routerInfo)-len(routerInfo.SignatureBytes()); length++ {
+		err := CheckNetworkID(routerInfo[:length], MainNetworkID)
+		if err == nil || err == ErrNetworkMismatch {
+			t.Fatalf("expected a parse error for RouterInfo truncated to %d bytes, got %v", length, err)
+		}
+	}
+}
+
+func TestMuxerGetSessionRejectsPeerOnOtherNetwork(t *testing.T) {
+	tmux := Mux(&styleTransport{style: "NTCP2"})
+	for _, netID := range []string{"3", ""} {
+		_, err := tmux.GetSession(buildRouterInfoOnNetwork(t, netID))
+		if err != ErrNetworkMismatch {
+			t.Fatalf("expected ErrNetworkMismatch for netId %q, got %v", netID, err)
+		}
+	}
+}
+
+func TestMuxerGetSessionAdmitsPeerOnConfiguredNetwork(t *testing.T) {
+	tmux := Mux(&styleTransport{style: "NTCP2"})
+	tmux.SetNetworkID(3)
+	s, err := tmux.GetSession(buildRouterInfoOnNetwork(t, "3"))
+	if err != nil {
+		t.Fatalf("GetSession failed: %s", err)
+	}
+	s.Close()
+	if _, err = tmux.GetSession(testutil.MinimalRouterInfo(t)); err != ErrNetworkMismatch {
+		t.Fatalf("expected ErrNetworkMismatch for a main network peer, got %v", err)
+	}
+}
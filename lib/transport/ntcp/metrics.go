@@ -0,0 +1,22 @@
+package ntcp
+
+import (
+	"github.com/go-i2p/go-i2p/lib/transport"
+)
+
+// report this transport's handshakes and traffic to m
+// a nil m stops reporting
+func (t *Transport) SetMetrics(m transport.Metrics) {
+	if m == nil {
+		m = transport.NopMetrics{}
+	}
+	t.metrics = m
+}
+
+// the Metrics this transport reports to
+func (t *Transport) getMetrics() transport.Metrics {
+	if t.metrics == nil {
+		return transport.NopMetrics{}
+	}
+	return t.metrics
+}
@@ -0,0 +1,77 @@
+package ntcp
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"testing"
+)
+
+// Metrics that records what it was told
+type recordingMetrics struct {
+	succeeded int
+	failed    []error
+	sent      int
+	received  int
+}
+
+func (m *recordingMetrics) HandshakeSucceeded()       { m.succeeded++ }
+func (m *recordingMetrics) HandshakeFailed(err error) { m.failed = append(m.failed, err) }
+func (m *recordingMetrics) BytesSent(n int)           { m.sent += n }
+func (m *recordingMetrics) BytesReceived(n int)       { m.received += n }
+
+func TestMetricsCountHandshakeAndTraffic(t *testing.T) {
+	assert := assert.New(t)
+
+	aliceMetrics := new(recordingMetrics)
+	bobMetrics := new(recordingMetrics)
+	aliceTransport := new(Transport)
+	aliceTransport.SetMetrics(aliceMetrics)
+	bobTransport := new(Transport)
+	bobTransport.SetMetrics(bobMetrics)
+	alice, bob := newSessionPairOn(t, aliceTransport, bobTransport)
+	assert.Equal(1, aliceMetrics.succeeded)
+	assert.Equal(1, bobMetrics.succeeded)
+
+	errs := make(chan error, 1)
+	go func() { errs <- alice.writeFrame(make([]byte, 100)) }()
+	_, err := bob.readFrame()
+	assert.Nil(err)
+	assert.Nil(<-errs)
+	// 2 byte length, payload and MAC
+	assert.Equal(2+100+16, aliceMetrics.sent)
+	assert.Equal(2+100+16, bobMetrics.received)
+	assert.Equal(0, aliceMetrics.received)
+	assert.Equal(0, bobMetrics.sent)
+	assert.Equal(0, len(aliceMetrics.failed)+len(bobMetrics.failed))
+}
+
+func TestMetricsCountFailedHandshake(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := new(recordingMetrics)
+	tr := new(Transport)
+	tr.SetMetrics(metrics)
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	handshakeErr := errors.New("bad handshake")
+	s, err := tr.handshakeDone(conn, dataPhaseKeys{}, handshakeErr)
+	assert.Nil(s)
+	assert.Equal(handshakeErr, err)
+	assert.Equal([]error{handshakeErr}, metrics.failed)
+	assert.Equal(0, metrics.succeeded)
+	_, err = conn.Write([]byte{0x00})
+	assert.Equal(io.ErrClosedPipe, err, "connection of a failed handshake was not closed")
+}
+
+func TestMetricsDefaultToNop(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := new(Transport)
+	tr.SetMetrics(nil)
+	alice, bob := newSessionPairOn(t, tr, new(Transport))
+	go alice.writeFrame([]byte("frame"))
+	_, err := bob.readFrame()
+	assert.Nil(err)
+}
@@ -0,0 +1,43 @@
+package ntcp
+
+import (
+	"errors"
+	"math"
+)
+
+// the largest nonce a data phase frame may use, the counter must never wrap
+// around to reuse a ChaCha20-Poly1305 nonce under the same key
+const MaxFrameNonce = math.MaxUint64 - 1
+
+// error returned once a session has used every nonce available to it
+// the session must be terminated as it cannot send or receive any more frames
+var ErrNonceExhausted = errors.New("ntcp: data phase nonce exhausted")
+
+// counts frames in one direction of the data phase, yielding the AEAD nonce for
+// each frame in turn
+type frameCounter struct {
+	// nonce for the next frame
+	next uint64
+	// largest nonce this counter will hand out
+	limit uint64
+}
+
+// create a frame counter that hands out nonces 0 through MaxFrameNonce
+func newFrameCounter() *frameCounter {
+	return &frameCounter{
+		limit: MaxFrameNonce,
+	}
+}
+
+// get the nonce for the next frame
+// returns ErrNonceExhausted once the limit has been reached, the counter stays
+// exhausted after that
+func (c *frameCounter) Next() (nonce uint64, err error) {
+	if c.next > c.limit {
+		err = ErrNonceExhausted
+		return
+	}
+	nonce = c.next
+	c.next++
+	return
+}
@@ -0,0 +1,35 @@
+package ntcp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFrameCounterStartsAtZero(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newFrameCounter()
+	for i := uint64(0); i < 3; i++ {
+		nonce, err := c.Next()
+		assert.Nil(err)
+		assert.Equal(i, nonce)
+	}
+}
+
+func TestFrameCounterTerminatesAtLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newFrameCounter()
+	c.next = MaxFrameNonce - 1
+	nonce, err := c.Next()
+	assert.Nil(err)
+	assert.Equal(uint64(MaxFrameNonce-1), nonce)
+	nonce, err = c.Next()
+	assert.Nil(err)
+	assert.Equal(uint64(MaxFrameNonce), nonce)
+
+	_, err = c.Next()
+	assert.Equal(ErrNonceExhausted, err, "frame counter did not report exhaustion past the limit")
+	_, err = c.Next()
+	assert.Equal(ErrNonceExhausted, err, "frame counter recovered after exhaustion")
+}
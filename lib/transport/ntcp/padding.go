@@ -0,0 +1,45 @@
+package ntcp
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// chooses how much padding to add to a frame so its size says less about its contents
+type PaddingStrategy interface {
+	// number of padding bytes to add to a frame carrying n bytes of payload
+	PaddingLength(n int) (int, error)
+}
+
+// pads frames up to the next multiple of BlockSize and then by a random number of
+// further whole blocks, so padded sizes fall on a few block boundaries instead of
+// spreading uniformly like random byte counts do
+type DistributionPaddingStrategy struct {
+	// frames are padded to a multiple of this many bytes
+	BlockSize int
+	// most extra blocks of padding chosen uniformly at random for each frame
+	MaxJitterBlocks int
+	// source of randomness for the jitter, crypto/rand when nil
+	Rand io.Reader
+}
+
+// number of padding bytes to add to a frame carrying n bytes of payload
+func (p *DistributionPaddingStrategy) PaddingLength(n int) (padding int, err error) {
+	if p.BlockSize > 0 {
+		padding = (p.BlockSize - n%p.BlockSize) % p.BlockSize
+	}
+	if p.MaxJitterBlocks > 0 && p.BlockSize > 0 {
+		r := p.Rand
+		if r == nil {
+			r = rand.Reader
+		}
+		var jitter *big.Int
+		jitter, err = rand.Int(r, big.NewInt(int64(p.MaxJitterBlocks)+1))
+		if err != nil {
+			return
+		}
+		padding += int(jitter.Int64()) * p.BlockSize
+	}
+	return
+}
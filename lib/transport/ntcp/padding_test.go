@@ -0,0 +1,47 @@
+package ntcp
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDistributionPaddingFillsToBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &DistributionPaddingStrategy{BlockSize: 64}
+	for _, n := range []int{0, 1, 63, 64, 65, 1000} {
+		padding, err := p.PaddingLength(n)
+		assert.Nil(err)
+		assert.Equal(0, (n+padding)%64, "payload of %d bytes not padded to a block", n)
+		assert.True(padding < 64, "payload of %d bytes padded by a whole block", n)
+	}
+}
+
+func TestDistributionPaddingJitterStaysInBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &DistributionPaddingStrategy{BlockSize: 16, MaxJitterBlocks: 3}
+	seen := make(map[int]bool)
+	for i := 0; i < 256; i++ {
+		padding, err := p.PaddingLength(10)
+		assert.Nil(err)
+		assert.Equal(0, (10+padding)%16)
+		extra := (10 + padding - 16) / 16
+		assert.True(extra >= 0 && extra <= 3, "jitter of %d blocks out of bounds", extra)
+		seen[extra] = true
+	}
+	assert.Equal(4, len(seen), "jitter did not cover every block count")
+}
+
+func TestDistributionPaddingUsesRand(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &DistributionPaddingStrategy{BlockSize: 16, MaxJitterBlocks: 255, Rand: bytes.NewReader([]byte{0x02})}
+	padding, err := p.PaddingLength(16)
+	assert.Nil(err)
+	assert.Equal(32, padding)
+
+	_, err = p.PaddingLength(16)
+	assert.NotNil(err, "exhausted rand did not report an error")
+}
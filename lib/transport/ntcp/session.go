@@ -1,6 +1,151 @@
 package ntcp
 
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"github.com/go-i2p/go-i2p/lib/transport"
+	"golang.org/x/crypto/chacha20poly1305"
+	"io"
+	"net"
+)
+
+// largest frame the 2 byte length field of the data phase can describe
+const MaxFrameSize = 65535
+
+// error returned when a frame is too big to send or too small to hold its MAC
+var ErrBadFrameSize = errors.New("ntcp: bad data phase frame size")
+
+// keys derived by the handshake for the data phase, one per direction
+type dataPhaseKeys struct {
+	// ChaCha20-Poly1305 key for frames we send
+	sendKey [chacha20poly1305.KeySize]byte
+	// ChaCha20-Poly1305 key for frames we receive
+	recvKey [chacha20poly1305.KeySize]byte
+	// SipHash keys and IV obfuscating the length of frames we send
+	sendSipKeys [SipKeySize]byte
+	// SipHash keys and IV obfuscating the length of frames we receive
+	recvSipKeys [SipKeySize]byte
+}
+
 // Session implements TransportSession
 // An established transport session
 type Session struct {
+	// connection to the peer
+	conn net.Conn
+	// where traffic counts are reported
+	metrics transport.Metrics
+	// encrypts frames we send in the data phase
+	sendCipher cipher.AEAD
+	// decrypts frames we receive in the data phase
+	recvCipher cipher.AEAD
+	// nonces for frames we send in the data phase
+	sendNonces *frameCounter
+	// nonces for frames we receive in the data phase
+	recvNonces *frameCounter
+	// obfuscates the length of frames we send
+	sendLength *lengthObfuscator
+	// recovers the length of frames we receive
+	recvLength *lengthObfuscator
+}
+
+// finish establishing a session over conn once the handshake on it has run
+// dialing and accepting both end here so each handshake is reported exactly once
+// a failed handshake closes conn, a successful one starts the data phase with keys
+func (t *Transport) handshakeDone(conn net.Conn, keys dataPhaseKeys, handshakeErr error) (s *Session, err error) {
+	metrics := t.getMetrics()
+	if handshakeErr == nil {
+		s, handshakeErr = newSession(conn, keys, metrics)
+	}
+	if handshakeErr != nil {
+		conn.Close()
+		metrics.HandshakeFailed(handshakeErr)
+		err = handshakeErr
+		return
+	}
+	metrics.HandshakeSucceeded()
+	return
+}
+
+// start the data phase over conn with the keys from a completed handshake
+func newSession(conn net.Conn, keys dataPhaseKeys, metrics transport.Metrics) (s *Session, err error) {
+	s = &Session{
+		conn:       conn,
+		metrics:    metrics,
+		sendNonces: newFrameCounter(),
+		recvNonces: newFrameCounter(),
+		sendLength: newLengthObfuscator(keys.sendSipKeys),
+		recvLength: newLengthObfuscator(keys.recvSipKeys),
+	}
+	s.sendCipher, err = chacha20poly1305.New(keys.sendKey[:])
+	if err == nil {
+		s.recvCipher, err = chacha20poly1305.New(keys.recvKey[:])
+	}
+	if err != nil {
+		s = nil
+	}
+	return
+}
+
+// the 12 byte AEAD nonce for a frame, 4 zero bytes then the little endian counter
+func frameNonce(n uint64) (nonce [chacha20poly1305.NonceSize]byte) {
+	binary.LittleEndian.PutUint64(nonce[4:], n)
+	return
+}
+
+// encrypt payload into the next frame and write it to the peer
+// the session is closed once its nonces are exhausted, it must not reuse one
+func (s *Session) writeFrame(payload []byte) (err error) {
+	if len(payload)+s.sendCipher.Overhead() > MaxFrameSize {
+		err = ErrBadFrameSize
+		return
+	}
+	n, err := s.sendNonces.Next()
+	if err != nil {
+		s.Close()
+		return
+	}
+	nonce := frameNonce(n)
+	frame := make([]byte, 2, 2+len(payload)+s.sendCipher.Overhead())
+	frame = s.sendCipher.Seal(frame, nonce[:], payload, nil)
+	masked := s.sendLength.Mask(uint16(len(frame) - 2))
+	copy(frame, masked[:])
+	written, err := s.conn.Write(frame)
+	s.metrics.BytesSent(written)
+	return
+}
+
+// read the next frame from the peer and return its decrypted payload
+// the session is closed once its nonces are exhausted, it must not reuse one
+func (s *Session) readFrame() (payload []byte, err error) {
+	var length [2]byte
+	read, err := io.ReadFull(s.conn, length[:])
+	s.metrics.BytesReceived(read)
+	if err != nil {
+		return
+	}
+	size := int(s.recvLength.Unmask(length))
+	if size < s.recvCipher.Overhead() {
+		err = ErrBadFrameSize
+		return
+	}
+	frame := make([]byte, size)
+	read, err = io.ReadFull(s.conn, frame)
+	s.metrics.BytesReceived(read)
+	if err != nil {
+		return
+	}
+	n, err := s.recvNonces.Next()
+	if err != nil {
+		s.Close()
+		return
+	}
+	nonce := frameNonce(n)
+	payload, err = s.recvCipher.Open(frame[:0], nonce[:], frame, nil)
+	return
+}
+
+// close the session and its connection
+func (s *Session) Close() error {
+	return s.conn.Close()
 }
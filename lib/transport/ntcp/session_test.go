@@ -0,0 +1,127 @@
+package ntcp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"testing"
+)
+
+// keys for alice's end of a data phase and the matching keys for bob's end
+func testDataPhaseKeys() (alice, bob dataPhaseKeys) {
+	for i := range alice.sendKey {
+		alice.sendKey[i] = byte(i)
+		alice.recvKey[i] = byte(0xff - i)
+	}
+	for i := range alice.sendSipKeys {
+		alice.sendSipKeys[i] = byte(i)
+		alice.recvSipKeys[i] = byte(0x80 + i)
+	}
+	bob.sendKey, bob.recvKey = alice.recvKey, alice.sendKey
+	bob.sendSipKeys, bob.recvSipKeys = alice.recvSipKeys, alice.sendSipKeys
+	return
+}
+
+// the two ends of a data phase over an in memory connection, established by
+// completed handshakes on the given transports
+func newSessionPairOn(t *testing.T, aliceTransport, bobTransport *Transport) (alice, bob *Session) {
+	aliceKeys, bobKeys := testDataPhaseKeys()
+	aliceConn, bobConn := net.Pipe()
+	alice, err := aliceTransport.handshakeDone(aliceConn, aliceKeys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err = bobTransport.handshakeDone(bobConn, bobKeys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		alice.Close()
+		bob.Close()
+	})
+	return
+}
+
+// the two ends of a data phase over an in memory connection
+func newSessionPair(t *testing.T) (alice, bob *Session) {
+	return newSessionPairOn(t, new(Transport), new(Transport))
+}
+
+func TestSessionFramesRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, bob := newSessionPair(t)
+	payloads := [][]byte{[]byte("first frame"), {}, make([]byte, 1024)}
+	errs := make(chan error, 1)
+	go func() {
+		for _, payload := range payloads {
+			if err := alice.writeFrame(payload); err != nil {
+				errs <- err
+				return
+			}
+		}
+		errs <- nil
+	}()
+	for _, payload := range payloads {
+		received, err := bob.readFrame()
+		assert.Nil(err)
+		assert.Equal(payload, received)
+	}
+	assert.Nil(<-errs)
+}
+
+func TestSessionTerminatesOnSendNonceExhaustion(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, _ := newSessionPair(t)
+	alice.sendNonces.next = MaxFrameNonce + 1
+	assert.Equal(ErrNonceExhausted, alice.writeFrame([]byte("frame")))
+	_, err := alice.conn.Write([]byte{0x00})
+	assert.Equal(io.ErrClosedPipe, err, "session was not terminated")
+}
+
+func TestSessionTerminatesOnReceiveNonceExhaustion(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, bob := newSessionPair(t)
+	bob.recvNonces.next = MaxFrameNonce + 1
+	go alice.writeFrame([]byte("frame"))
+	_, err := bob.readFrame()
+	assert.Equal(ErrNonceExhausted, err)
+	_, err = bob.conn.Read(make([]byte, 1))
+	assert.Equal(io.ErrClosedPipe, err, "session was not terminated")
+}
+
+func TestSessionRejectsOversizedFrame(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, _ := newSessionPair(t)
+	assert.Equal(ErrBadFrameSize, alice.writeFrame(make([]byte, MaxFrameSize)))
+}
+
+func TestSessionObfuscatesFrameLengths(t *testing.T) {
+	assert := assert.New(t)
+
+	alice, bob := newSessionPair(t)
+	go func() {
+		alice.writeFrame([]byte("same"))
+		alice.writeFrame([]byte("same"))
+	}()
+	// read the raw frames and undo the masking with our own copy of alice's keystream
+	var sipKeys [SipKeySize]byte
+	for i := range sipKeys {
+		sipKeys[i] = byte(i)
+	}
+	recvLength := newLengthObfuscator(sipKeys)
+	var lengths [][2]byte
+	for i := 0; i < 2; i++ {
+		frame := make([]byte, 2+4+16)
+		_, err := io.ReadFull(bob.conn, frame)
+		assert.Nil(err)
+		var length [2]byte
+		copy(length[:], frame)
+		lengths = append(lengths, length)
+		assert.Equal(uint16(4+16), recvLength.Unmask(length))
+	}
+	assert.NotEqual(lengths[0], lengths[1], "equal lengths were not masked differently")
+}
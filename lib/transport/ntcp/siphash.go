@@ -0,0 +1,97 @@
+package ntcp
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// size of the SipHash key material derived for each direction of the data phase
+// k1 and k2 followed by the initial IV, all 8 bytes
+const SipKeySize = 24
+
+// obfuscates the 2 byte frame length field in one direction of the data phase
+// each frame advances the IV with SipHash-2-4 and masks the length with the
+// first 2 bytes of the new IV
+type lengthObfuscator struct {
+	k0, k1 uint64
+	iv     uint64
+}
+
+// create a length obfuscator from the sip keys derived during the handshake
+func newLengthObfuscator(sipKeys [SipKeySize]byte) *lengthObfuscator {
+	return &lengthObfuscator{
+		k0: binary.LittleEndian.Uint64(sipKeys[0:8]),
+		k1: binary.LittleEndian.Uint64(sipKeys[8:16]),
+		iv: binary.LittleEndian.Uint64(sipKeys[16:24]),
+	}
+}
+
+// advance the IV and return the mask for the next frame
+func (o *lengthObfuscator) nextMask() uint16 {
+	var msg [8]byte
+	binary.LittleEndian.PutUint64(msg[:], o.iv)
+	o.iv = sipHash24(o.k0, o.k1, msg[:])
+	binary.LittleEndian.PutUint64(msg[:], o.iv)
+	return binary.BigEndian.Uint16(msg[:2])
+}
+
+// mask the length of the next frame to be sent
+func (o *lengthObfuscator) Mask(length uint16) (masked [2]byte) {
+	binary.BigEndian.PutUint16(masked[:], length^o.nextMask())
+	return
+}
+
+// recover the length of the next frame received
+func (o *lengthObfuscator) Unmask(masked [2]byte) uint16 {
+	return binary.BigEndian.Uint16(masked[:]) ^ o.nextMask()
+}
+
+// SipHash-2-4 of msg under the key k0, k1
+func sipHash24(k0, k1 uint64, msg []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13)
+		v1 ^= v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16)
+		v3 ^= v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21)
+		v3 ^= v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17)
+		v1 ^= v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	length := len(msg)
+	for len(msg) >= 8 {
+		m := binary.LittleEndian.Uint64(msg)
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+		msg = msg[8:]
+	}
+	var last [8]byte
+	copy(last[:], msg)
+	last[7] = byte(length)
+	m := binary.LittleEndian.Uint64(last[:])
+	v3 ^= m
+	round()
+	round()
+	v0 ^= m
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
@@ -0,0 +1,36 @@
+package ntcp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSipHash24ReferenceVector(t *testing.T) {
+	assert := assert.New(t)
+
+	// key 00..0f and message 00..07 from the SipHash paper
+	msg := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}
+	assert.Equal(uint64(0x93f5f5799a932462), sipHash24(0x0706050403020100, 0x0f0e0d0c0b0a0908, msg))
+	assert.Equal(uint64(0x726fdb47dd0e0e31), sipHash24(0x0706050403020100, 0x0f0e0d0c0b0a0908, nil))
+}
+
+func TestLengthObfuscatorRoundTrips(t *testing.T) {
+	assert := assert.New(t)
+
+	var sipKeys [SipKeySize]byte
+	for i := range sipKeys {
+		sipKeys[i] = byte(i)
+	}
+	sender := newLengthObfuscator(sipKeys)
+	receiver := newLengthObfuscator(sipKeys)
+
+	lengths := []uint16{16, 16, 1024, 65535}
+	masks := make(map[[2]byte]bool)
+	for _, length := range lengths {
+		masked := sender.Mask(length)
+		masks[masked] = true
+		assert.Equal(length, receiver.Unmask(masked))
+	}
+	// the same length is masked differently in consecutive frames
+	assert.Equal(len(lengths), len(masks), "keystream did not advance between frames")
+}
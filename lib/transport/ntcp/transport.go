@@ -1,5 +1,11 @@
 package ntcp
 
+import (
+	"github.com/go-i2p/go-i2p/lib/transport"
+)
+
 // Transport is an ntcp transport implementing transport.Transport interface
 type Transport struct {
+	// where handshake and traffic counts are reported
+	metrics transport.Metrics
 }
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common"
+	"sync"
+)
+
+// a set of transports registered by name so transports from outside this package can be
+// plugged into a TransportMuxer
+// safe for concurrent use
+type Registry struct {
+	mtx sync.RWMutex
+	// registered names in the order they were registered
+	names      []string
+	transports map[string]Transport
+}
+
+// create an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		transports: make(map[string]Transport),
+	}
+}
+
+// register a transport under a name
+// returns ErrTransportRegistered if a transport is already registered under that name
+func (r *Registry) Register(name string, t Transport) (err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.transports[name]; ok {
+		err = ErrTransportRegistered
+		return
+	}
+	r.names = append(r.names, name)
+	r.transports[name] = t
+	return
+}
+
+// get the transport registered under a name
+func (r *Registry) Get(name string) (t Transport, ok bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	t, ok = r.transports[name]
+	return
+}
+
+// get every registered transport in the order they were registered
+func (r *Registry) Transports() (trans []Transport) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for _, name := range r.names {
+		trans = append(trans, r.transports[name])
+	}
+	return
+}
+
+// get the registered transports that are compatable with a router info in the order they
+// were registered
+func (r *Registry) CompatibleTransports(routerInfo common.RouterInfo) (trans []Transport) {
+	for _, t := range r.Transports() {
+		if t.Compatable(routerInfo) {
+			trans = append(trans, t)
+		}
+	}
+	return
+}
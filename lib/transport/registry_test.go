@@ -0,0 +1,54 @@
+package transport
+
+import (
+	"testing"
+)
+
+func TestRegistryResolvesCompatibleTransport(t *testing.T) {
+	r := NewRegistry()
+	ntcp2 := &styleTransport{style: "NTCP2"}
+	ssu2 := &styleTransport{style: "SSU2"}
+	if err := r.Register("ntcp2", ntcp2); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("ssu2", ssu2); err != nil {
+		t.Fatal(err)
+	}
+	compat := r.CompatibleTransports(buildRouterInfoWithStyle(t, "SSU2"))
+	if len(compat) != 1 || compat[0] != ssu2 {
+		t.Fatalf("expected only the ssu2 transport, got %v", compat)
+	}
+	if got, ok := r.Get("ntcp2"); !ok || got != ntcp2 {
+		t.Fatal("registered transport not found by name")
+	}
+}
+
+func TestRegistryRejectsDuplicateName(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("ntcp2", &styleTransport{style: "NTCP2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("ntcp2", &styleTransport{style: "NTCP2"}); err != ErrTransportRegistered {
+		t.Fatalf("expected ErrTransportRegistered, got %v", err)
+	}
+	if len(r.Transports()) != 1 {
+		t.Fatalf("expected 1 transport, got %d", len(r.Transports()))
+	}
+}
+
+func TestMuxRegistryUsesLateRegistrations(t *testing.T) {
+	r := NewRegistry()
+	tmux := MuxRegistry(r)
+	routerInfo := buildRouterInfoWithStyle(t, "NTCP2")
+	if _, err := tmux.GetSession(routerInfo); err != ErrNoCompatibleTransport {
+		t.Fatalf("expected ErrNoCompatibleTransport, got %v", err)
+	}
+	if err := r.Register("ntcp2", &styleTransport{style: "NTCP2"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := tmux.GetSession(routerInfo)
+	if err != nil {
+		t.Fatalf("GetSession failed: %s", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,63 @@
+// helpers for testing transport implementations
+package transporttest
+
+import (
+	"github.com/go-i2p/go-i2p/lib/i2np"
+	"github.com/go-i2p/go-i2p/lib/transport"
+	"testing"
+	"time"
+)
+
+// how long a conformance check waits on a blocking session call before failing
+const conformanceTimeout = 5 * time.Second
+
+// exercise every TransportSession method against a fresh session from factory
+// transports call this from their own tests so a missing or misbehaving method
+// shows up as a test failure rather than at runtime
+func TestTransportSessionConformance(t *testing.T, factory func() (transport.TransportSession, error)) {
+	t.Helper()
+	s, err := factory()
+	if err != nil {
+		t.Fatalf("session factory failed: %s", err)
+	}
+	if s == nil {
+		t.Fatal("session factory returned nil session")
+	}
+	if size := s.SendQueueSize(); size != 0 {
+		t.Errorf("new session has send queue size %d, expected 0", size)
+	}
+
+	// queueing must not block on an empty queue
+	queued := make(chan struct{})
+	go func() {
+		s.QueueSendI2NP(i2np.I2NPMessage{})
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(conformanceTimeout):
+		t.Fatal("QueueSendI2NP blocked with an empty send queue")
+	}
+	if size := s.SendQueueSize(); size < 0 || size > 1 {
+		t.Errorf("send queue size %d after queueing one message, expected 0 or 1", size)
+	}
+
+	if err = s.Close(); err != nil {
+		t.Errorf("Close returned error: %s", err)
+	}
+
+	// reading from a closed session must fail instead of blocking forever
+	read := make(chan error)
+	go func() {
+		_, rerr := s.ReadNextI2NP()
+		read <- rerr
+	}()
+	select {
+	case rerr := <-read:
+		if rerr == nil {
+			t.Error("ReadNextI2NP on a closed session returned no error")
+		}
+	case <-time.After(conformanceTimeout):
+		t.Fatal("ReadNextI2NP blocked on a closed session")
+	}
+}